@@ -0,0 +1,116 @@
+package workgroup
+
+import "context"
+
+// Acquirer is a weighted semaphore. It is implemented
+// by *semaphore.Weighted of the golang.org/x/sync module.
+type Acquirer interface {
+	Acquire(ctx context.Context, n int64) error
+	Release(n int64)
+}
+
+type semaphoreExecuter struct {
+	s    Acquirer
+	size int
+}
+
+// NewSemaphoreExecuter returns an executer that will execute each
+// function on a new goroutine once a unit of the semaphore, s, with
+// the given size, has been acquired, so that work groups share the
+// limit of a semaphore that is shared with the rest of the application.
+// If the unit is not acquired, for example, because the context of the
+// function is done first, then the function is called on the calling
+// goroutine with a cancelled context, and the work group of a worker
+// that is not executed handles it as if it completed with the error
+// returned by Acquire. The cost of a worker can be declared with the
+// Weighted worker wrapper, otherwise each function acquires one unit.
+// The cost is clamped to the size of the semaphore, so that the worker
+// can be admitted. If size <= 0 then the cost of each worker is one.
+func NewSemaphoreExecuter(s Acquirer, size int) Executer {
+	if size <= 0 {
+		size = 1
+	}
+	return &semaphoreExecuter{s: s, size: size}
+}
+
+func (e *semaphoreExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	if err := e.s.Acquire(ctx, 1); err != nil {
+		f(rejected(ctx, err))
+		return
+	}
+	go func() {
+		defer e.s.Release(1)
+		f(ctx)
+	}()
+}
+
+// weigh exchanges the unit held by a worker for n units, clamped to
+// the size of the semaphore, see limited.weigh(). If the units are not
+// acquired, then the unit held by the worker is reacquired.
+func (e *semaphoreExecuter) weigh(ctx context.Context, n int) (func(), error) {
+	if n > e.size {
+		n = e.size
+	}
+	if n <= 1 {
+		return func() {}, nil
+	}
+	e.s.Release(1)
+	if err := e.s.Acquire(ctx, int64(n)); err != nil {
+		_ = e.s.Acquire(context.Background(), 1)
+		return nil, err
+	}
+	return func() {
+		e.s.Release(int64(n - 1))
+	}, nil
+}
+
+// lend releases the unit held by the worker that is starting
+// a nested group with this executer, see limited.lend().
+func (e *semaphoreExecuter) lend() func() {
+	e.s.Release(1)
+	return func() {
+		_ = e.s.Acquire(context.Background(), 1)
+	}
+}
+
+// Submitter is a goroutine pool that runs the submitted functions, such
+// as *ants.Pool of the github.com/panjf2000/ants module. Submit returns
+// an error if the function is not accepted, for example, because the
+// pool is overloaded or closed.
+type Submitter interface {
+	Submit(f func()) error
+}
+
+// SubmitterFunc is an adapter to allow the use of an ordinary
+// function as a Submitter, for example, a function that sends
+// the functions to a custom pool.
+type SubmitterFunc func(f func()) error
+
+// Submit calls fn(f).
+func (fn SubmitterFunc) Submit(f func()) error {
+	return fn(f)
+}
+
+type submitExecuter struct {
+	p Submitter
+}
+
+// NewSubmitExecuter returns an executer that will execute functions on
+// the goroutine pool, p, so that work groups share a pool that is shared
+// with the rest of the application rather than create parallel limits.
+// If the pool does not accept a function, then the function is called
+// on the calling goroutine with a cancelled context, and the work group
+// of a worker that is not accepted handles it as if it completed with
+// the error returned by Submit.
+func NewSubmitExecuter(p Submitter) Executer {
+	return &submitExecuter{p: p}
+}
+
+func (e *submitExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	err := e.p.Submit(func() {
+		f(ctx)
+	})
+	if err != nil {
+		f(rejected(ctx, err))
+	}
+}
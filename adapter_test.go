@@ -0,0 +1,161 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testSemaphore is an Acquirer that records the peak units held.
+type testSemaphore struct {
+	mutex sync.Mutex
+	cond  *sync.Cond
+	size  int64
+	cur   int64
+	peak  int64
+}
+
+func newTestSemaphore(size int64) *testSemaphore {
+	s := &testSemaphore{size: size}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
+}
+
+func (s *testSemaphore) Acquire(ctx context.Context, n int64) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.mutex.Lock()
+			s.cond.Broadcast()
+			s.mutex.Unlock()
+		case <-stop:
+		}
+	}()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for s.cur+n > s.size {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.cond.Wait()
+	}
+	s.cur += n
+	if s.cur > s.peak {
+		s.peak = s.cur
+	}
+	return nil
+}
+
+func (s *testSemaphore) held() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cur
+}
+
+func (s *testSemaphore) Release(n int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cur -= n
+	s.cond.Broadcast()
+}
+
+func TestSemaphoreExecuter(t *testing.T) {
+
+	s := newTestSemaphore(4)
+	workers := make([]Worker, 100)
+	for i := range workers {
+		w := func(ctx context.Context) error {
+			return nil
+		}
+		if i%10 == 0 {
+			w = Weighted(3, w)
+		}
+		workers[i] = w
+	}
+
+	err := Work(context.Background(), NewSemaphoreExecuter(s, 4), nil, workers...)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+	if s.peak > 4 {
+		t.Fatalf("Units held exceeded the size of the semaphore: %d", s.peak)
+	}
+}
+
+func TestSemaphoreExecuterClamp(t *testing.T) {
+
+	// the cost of a worker is clamped to the size of the semaphore
+	s := newTestSemaphore(3)
+	err := Work(context.Background(), NewSemaphoreExecuter(s, 3), nil, Weighted(5, func(ctx context.Context) error {
+		return nil
+	}))
+	if err != nil || s.peak != 3 {
+		t.Fatalf("Expecting 3 units held, got %d: %v", s.peak, err)
+	}
+
+	// a worker that is not admitted is not invoked
+	s = newTestSemaphore(1)
+	s.Acquire(context.Background(), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Work(ctx, NewSemaphoreExecuter(s, 1), nil, func(ctx context.Context) error {
+		t.Error("Worker was invoked without a unit of the semaphore")
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expecting error %v, got %v", context.Canceled, err)
+	}
+
+	// the cost is acquired with the context of the worker
+	s = newTestSemaphore(3)
+	s.Acquire(context.Background(), 2)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = Work(ctx, NewSemaphoreExecuter(s, 3), nil, Weighted(3, func(ctx context.Context) error {
+		t.Error("Worker was invoked without its cost")
+		return nil
+	}))
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expecting error %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// the unit of the worker is released once it has returned
+	deadline := time.Now().Add(time.Second)
+	for s.held() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := s.held(); n != 2 {
+		t.Fatalf("Expecting 2 units held, got %d", n)
+	}
+}
+
+func TestSubmitExecuter(t *testing.T) {
+
+	overloaded := errors.New("overloaded")
+	var accepted int32
+	p := SubmitterFunc(func(f func()) error {
+		if atomic.AddInt32(&accepted, 1) > 3 {
+			return overloaded
+		}
+		go f()
+		return nil
+	})
+
+	var ran int32
+	err := WorkFor(context.Background(), NewSubmitExecuter(p), CancelNeverFirstError(), 5, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	})
+	if err != overloaded {
+		t.Fatalf("Expecting error %v, got %v", overloaded, err)
+	}
+	if ran != 3 {
+		t.Fatalf("Expecting 3 workers run, got %d", ran)
+	}
+}
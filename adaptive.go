@@ -0,0 +1,132 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type adaptiveKey struct{}
+
+// adaptiveTask records the outcome of a function
+// executed by an adaptive executer.
+type adaptiveTask struct {
+	failed bool
+}
+
+// AdaptiveExecuter is an executer that tunes its concurrency limit
+// using additive-increase/multiplicative-decrease (AIMD). The limit
+// is increased by about one for each round of successful functions,
+// and it is halved when a function is slower than the latency target
+// or when its worker fails, as reported by the Feedback manager.
+type AdaptiveExecuter struct {
+	mutex  sync.Mutex
+	min    int
+	max    int
+	target time.Duration
+	limit  float64
+	sem    *semaphore
+}
+
+// NewAdaptive returns an executer that will execute functions with
+// a concurrency limit that is adapted between min and max. If min < 1
+// then a minimum of one is used and if max < min then max is set to min.
+// If the target latency is greater than zero, then functions that take
+// longer are treated as a signal of overload.
+func NewAdaptive(min, max int, target time.Duration) *AdaptiveExecuter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &AdaptiveExecuter{
+		min:    min,
+		max:    max,
+		target: target,
+		limit:  float64(min),
+		sem:    newSemaphore(min),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (a *AdaptiveExecuter) Limit() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return int(a.limit)
+}
+
+func (a *AdaptiveExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	a.sem.acquire(1)
+	go func() {
+		defer a.sem.release(1)
+
+		t := &adaptiveTask{}
+		clock := ClockFrom(ctx)
+		start := clock.Now()
+		f(context.WithValue(ctx, adaptiveKey{}, t))
+		a.update(clock.Now().Sub(start), t.failed)
+	}()
+}
+
+// SetLimit sets the current concurrency limit to n, limited by
+// the minimum and maximum, from which the limit continues to adapt.
+func (a *AdaptiveExecuter) SetLimit(n int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if n < a.min {
+		n = a.min
+	}
+	if n > a.max {
+		n = a.max
+	}
+	a.limit = float64(n)
+	a.sem.resize(n)
+}
+
+func (a *AdaptiveExecuter) update(latency time.Duration, failed bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if failed || (a.target > 0 && latency > a.target) {
+		a.limit /= 2
+		if a.limit < float64(a.min) {
+			a.limit = float64(a.min)
+		}
+	} else {
+		a.limit += 1 / a.limit
+		if a.limit > float64(a.max) {
+			a.limit = float64(a.max)
+		}
+	}
+	a.sem.resize(int(a.limit))
+}
+
+type adaptiveFeedback struct {
+	m Manager
+}
+
+// Feedback wraps a Manager, m, and reports the workers that complete
+// with an error to the adaptive executer so that the concurrency
+// limit is decreased. If manager, m, is not provided then
+// DefaultManager is called to obtain the default manager.
+func (a *AdaptiveExecuter) Feedback(m Manager) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &adaptiveFeedback{m: m}
+}
+
+func (f *adaptiveFeedback) Error() error {
+	return f.m.Error()
+}
+
+func (f *adaptiveFeedback) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	if *err != nil {
+		if t, ok := ctx.Value(adaptiveKey{}).(*adaptiveTask); ok {
+			t.failed = true
+		}
+	}
+	return f.m.Manage(ctx, c, idx, err)
+}
@@ -0,0 +1,51 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAdaptiveExecuter(t *testing.T) {
+
+	a := NewAdaptive(1, 16, 0)
+
+	WorkFor(context.Background(), a, CancelNeverFirstError(), 1000,
+		func(ctx context.Context, index int) error {
+			return nil
+		},
+	)
+	if a.Limit() != 16 {
+		t.Fatalf("Expecting limit to increase to maximum, got %d", a.Limit())
+	}
+
+	WorkFor(context.Background(), a, a.Feedback(CancelNeverFirstError()), 10,
+		func(ctx context.Context, index int) error {
+			return errors.New("overloaded")
+		},
+	)
+	if a.Limit() != 1 {
+		t.Fatalf("Expecting limit to decrease to minimum, got %d", a.Limit())
+	}
+}
+
+func TestAdaptiveNested(t *testing.T) {
+
+	a := NewAdaptive(1, 16, 0)
+	a.SetLimit(16)
+
+	// the failures of nested workers are not recorded for the worker
+	WorkFor(context.Background(), a, CancelNeverFirstError(), 4,
+		func(ctx context.Context, index int) error {
+			WorkFor(ctx, NewUnlimited(), a.Feedback(CancelNeverFirstError()), 4,
+				func(ctx context.Context, index int) error {
+					return errors.New("overloaded")
+				},
+			)
+			return nil
+		},
+	)
+	if a.Limit() != 16 {
+		t.Fatalf("Expecting limit to remain at maximum, got %d", a.Limit())
+	}
+}
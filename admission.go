@@ -0,0 +1,47 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+type admissionKey struct{}
+
+// admission decides whether workers are started before the deadline.
+type admission struct {
+	estimate time.Duration
+	onSkip   func(int)
+}
+
+// WithDeadlineAdmission returns a copy of the context, ctx, that configures
+// the work groups started with it, and all work groups nested within them,
+// to stop submitting workers to the executer once the time remaining until
+// the deadline of the context is less than the duration, estimate, which
+// is the expected duration of a worker. A worker that is not submitted is
+// not invoked, the manager handles it as if it completed with the error,
+// context.DeadlineExceeded, and the optional function, onSkip, is called
+// with its index. This avoids spending downstream capacity on workers that
+// would be cancelled before they complete. Contexts without a deadline are
+// not affected. The time is measured by the clock of the context, see
+// WithClock.
+func WithDeadlineAdmission(ctx context.Context, estimate time.Duration, onSkip func(index int)) context.Context {
+	return context.WithValue(ctx, admissionKey{}, &admission{estimate: estimate, onSkip: onSkip})
+}
+
+func admissionFrom(ctx context.Context) *admission {
+	a, _ := ctx.Value(admissionKey{}).(*admission)
+	return a
+}
+
+// admit reports whether the worker with the given index can complete
+// before the deadline of the context, ctx, and reports it if not.
+func (a *admission) admit(ctx context.Context, index int) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok || deadline.Sub(ClockFrom(ctx).Now()) >= a.estimate {
+		return true
+	}
+	if a.onSkip != nil {
+		a.onSkip(index)
+	}
+	return false
+}
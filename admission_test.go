@@ -0,0 +1,49 @@
+package workgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeadlineAdmission(t *testing.T) {
+
+	var skipped []int
+
+	// The clock is advanced by 40ms for each worker that is executed,
+	// the deadline is 100ms after the initial time of the clock.
+	deadline := time.Now().Add(time.Hour)
+	clock := &steppedClock{step: 40 * time.Millisecond, now: deadline.Add(-100 * time.Millisecond)}
+	ctx, cancel := context.WithDeadline(WithClock(context.Background(), clock), deadline)
+	defer cancel()
+	ctx = WithDeadlineAdmission(ctx, 30*time.Millisecond, func(index int) {
+		skipped = append(skipped, index)
+	})
+
+	invoked := make([]bool, 5)
+	err := WorkFor(ctx, clock, CancelNeverFirstError(), 5, func(ctx context.Context, index int) error {
+		invoked[index] = true
+		return nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expecting deadline exceeded, got %v", err)
+	}
+
+	// Workers 0 and 1 are admitted with 100ms and 60ms
+	// remaining, worker 2 is submitted with 20ms remaining.
+	if len(skipped) != 3 || skipped[0] != 2 {
+		t.Fatalf("Expecting workers 2 to 4 skipped, got %v", skipped)
+	}
+	for index, ok := range invoked {
+		if ok != (index < 2) {
+			t.Fatalf("Expecting workers 0 and 1 invoked, got %v", invoked)
+		}
+	}
+
+	err = Work(context.Background(), nil, nil, func(ctx context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error without deadline: %v", err)
+	}
+}
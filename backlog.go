@@ -0,0 +1,23 @@
+package workgroup
+
+import "context"
+
+type backlogKey struct{}
+
+// WithBacklog returns a copy of the context, ctx, that configures the
+// work group started with it to report its backlog to the function, fn.
+// The backlog is the number of workers that have been submitted to the
+// group but have not yet completed, and it is reported each time a
+// worker is submitted or completes. A producer of workers for WorkChan
+// that can not block on sending to the channel can use the backlog to
+// slow down, for example, by widening its poll interval. The function
+// is called while the group is locked and should return quickly.
+// Work groups nested in the group do not report to the function.
+func WithBacklog(ctx context.Context, fn func(backlog int)) context.Context {
+	return context.WithValue(ctx, backlogKey{}, fn)
+}
+
+func backlogFrom(ctx context.Context) func(int) {
+	fn, _ := ctx.Value(backlogKey{}).(func(int))
+	return fn
+}
@@ -0,0 +1,47 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWorkChanBacklog(t *testing.T) {
+
+	var max, last int
+	ctx := WithBacklog(context.Background(), func(backlog int) {
+		if backlog > max {
+			max = backlog
+		}
+		last = backlog
+	})
+
+	block := make(chan struct{})
+	g := make(chan Worker)
+	go func() {
+		defer close(g)
+		for i := 0; i < 5; i++ {
+			g <- func(ctx context.Context) error {
+				<-block
+				return nil
+			}
+		}
+		g <- GroupFor(nil, nil, 3, func(ctx context.Context, index int) error {
+			if backlogFrom(ctx) != nil {
+				return errors.New("nested group inherited the backlog function")
+			}
+			return nil
+		})
+		close(block)
+	}()
+
+	if err := WorkChan(ctx, nil, nil, g); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if max != 6 {
+		t.Fatalf("Expecting maximum backlog of 6, got %d", max)
+	}
+	if last != 0 {
+		t.Fatalf("Expecting final backlog of 0, got %d", last)
+	}
+}
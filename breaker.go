@@ -0,0 +1,133 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error of a worker that was not invoked
+// because the circuit breaker of its work group is open.
+var ErrCircuitOpen = errors.New("workgroup: circuit open")
+
+// admitter is implemented by managers that decide whether a
+// worker is invoked, the result is the error of a worker that
+// is not invoked. The work group consults its manager, and the
+// Recover and Repanic wrappers consult the manager they wrap.
+type admitter interface {
+	admit(ctx context.Context, idx int) error
+}
+
+// BreakerOption configures optional behavior of a circuit breaker.
+type BreakerOption func(*breaker)
+
+// BreakerThreshold configures the circuit breaker to open after n
+// consecutive workers have completed with an error, the default is 5.
+func BreakerThreshold(n int) BreakerOption {
+	return func(b *breaker) {
+		b.threshold = n
+	}
+}
+
+// BreakerHalfOpen configures the circuit breaker to admit a single worker,
+// to probe whether the failure has recovered, once it has been open for
+// the duration, d. If the worker completes without error then the breaker
+// closes, otherwise it opens again. By default the breaker stays open.
+func BreakerHalfOpen(d time.Duration) BreakerOption {
+	return func(b *breaker) {
+		b.halfOpen = d
+	}
+}
+
+type breaker struct {
+	m         Manager
+	threshold int
+	halfOpen  time.Duration
+
+	mutex    sync.Mutex
+	failures int
+	open     bool
+	opened   time.Time
+	probe    int
+	probing  bool
+}
+
+// CircuitBreaker wraps a Manager, m, and opens the circuit after a number
+// of consecutive workers have completed with an error, see BreakerThreshold.
+// While the circuit is open, workers are not invoked and are passed to the
+// wrapped manager as if they completed with the error, ErrCircuitOpen,
+// which is useful for large groups of workers that share a downstream
+// dependency. The circuit breaker must be the outermost manager, or be
+// wrapped by Recover or Repanic. If manager, m, is not provided then
+// DefaultManager is called to obtain the default manager. The time is
+// measured by the clock of the context, see WithClock.
+func CircuitBreaker(m Manager, opts ...BreakerOption) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	b := &breaker{m: m, threshold: 5}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *breaker) admit(ctx context.Context, idx int) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	if b.halfOpen > 0 && !b.probing && ClockFrom(ctx).Now().Sub(b.opened) >= b.halfOpen {
+		b.probing = true
+		b.probe = idx
+		return nil
+	}
+	return ErrCircuitOpen
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (b *breaker) NeverCancels() bool {
+	return neverCancels(b.m)
+}
+
+func (b *breaker) Error() error {
+	return b.m.Error()
+}
+
+func (b *breaker) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	b.record(ctx, idx, *err)
+	return b.m.Manage(ctx, c, idx, err)
+}
+
+// record records the outcome of the worker with the given index.
+func (b *breaker) record(ctx context.Context, idx int, err error) {
+	if errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.probing && idx == b.probe {
+		b.probing = false
+		if err == nil {
+			b.open = false
+			b.failures = 0
+		} else {
+			b.opened = ClockFrom(ctx).Now()
+		}
+		return
+	}
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if !b.open && b.failures >= b.threshold {
+		b.open = true
+		b.opened = ClockFrom(ctx).Now()
+	}
+}
@@ -0,0 +1,79 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	var calls int32
+	m := CircuitBreaker(Accumulate(CancelNeverFirstError()), BreakerThreshold(3))
+	WorkFor(context.Background(), NewSerial(), m, 10, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&calls, 1)
+		return failed
+	})
+	if calls != 3 {
+		t.Fatalf("Expecting 3 workers invoked before the circuit opened, got %d", calls)
+	}
+	errs := m.(*breaker).m.(*AccumulateManager).Errors()
+	if len(errs) != 10 || errs[3] != ErrCircuitOpen {
+		t.Fatalf("Expecting short-circuited workers to fail with ErrCircuitOpen: %v", errs)
+	}
+}
+
+// steppedClock is a clock that is advanced by a step for each
+// function executed by its executer.
+type steppedClock struct {
+	systemClock
+	step time.Duration
+	now  time.Time
+}
+
+func (c *steppedClock) Now() time.Time {
+	return c.now
+}
+
+func (c *steppedClock) Execute(ctx context.Context, f func(context.Context)) {
+	c.now = c.now.Add(c.step)
+	f(ctx)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	// The first worker fails and opens the circuit, the worker that
+	// probes after the circuit is half-open fails and opens it again,
+	// the worker that probes next succeeds and closes the circuit.
+	var invoked []int
+	clock := &steppedClock{step: 3 * time.Millisecond}
+	m := Recover(CircuitBreaker(CancelNeverFirstError(), BreakerThreshold(1), BreakerHalfOpen(5*time.Millisecond)))
+	WorkFor(WithClock(context.Background(), clock), clock, m, 8, func(ctx context.Context, index int) error {
+		invoked = append(invoked, index)
+		if len(invoked) < 3 {
+			return failed
+		}
+		return nil
+	})
+	if len(invoked) != 6 || invoked[0] != 0 || invoked[1] != 2 || invoked[2] != 4 {
+		t.Fatalf("Expecting workers 1 and 3 short-circuited, got %v", invoked)
+	}
+}
+
+func TestCircuitBreakerNamed(t *testing.T) {
+
+	// The errors of a named group are annotated as WorkerError,
+	// a short-circuited worker does not count as a failure.
+	b := CircuitBreaker(CancelNeverFirstError(), BreakerThreshold(1)).(*breaker)
+	err := error(&WorkerError{Index: 0, Group: "named", Err: ErrCircuitOpen})
+	b.Manage(context.Background(), nil, 0, &err)
+	if b.open {
+		t.Fatalf("Expecting circuit closed after a short-circuited worker")
+	}
+}
@@ -0,0 +1,172 @@
+package workgroup
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrorBudget is an executer that pauses the admission of functions
+// when the error rate of the workers exceeds a threshold, rather than
+// cancelling the work group, and then resumes at a reduced concurrency
+// limit, for "degrade, don't die" semantics in long running groups.
+// The errors of the workers are reported by the Manager of the budget.
+type ErrorBudget struct {
+	mutex     sync.Mutex
+	max       int
+	limit     int
+	threshold float64
+	cooldown  time.Duration
+	outcomes  []bool
+	next      int
+	nerrors   int
+	nsuccess  int
+	paused    bool
+	sem       *semaphore
+}
+
+// NewErrorBudget returns an executer that will execute functions on at
+// most limit goroutines simultaneously. When the fraction of the last
+// window of completed workers that failed exceeds the threshold, then
+// admission is paused for the cooldown, after which the limit is halved.
+// The limit is increased by one, up to the initial limit, for each
+// subsequent window of workers that complete without error. If limit
+// <= 0 then the value provided by DefaultLimit will be used, and if
+// window < 1 then a window of one worker is used.
+func NewErrorBudget(limit int, threshold float64, window int, cooldown time.Duration) *ErrorBudget {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit <= 0 {
+		limit = runtime.NumCPU()
+	}
+	if window < 1 {
+		window = 1
+	}
+	return &ErrorBudget{
+		max:       limit,
+		limit:     limit,
+		threshold: threshold,
+		cooldown:  cooldown,
+		outcomes:  make([]bool, 0, window),
+		sem:       newSemaphore(limit),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (b *ErrorBudget) Limit() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.limit
+}
+
+// Paused reports whether admission is paused.
+func (b *ErrorBudget) Paused() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.paused
+}
+
+// Execute executes the function, f, on a new goroutine once it is
+// admitted. If the context, ctx, is done while the function waits to be
+// admitted, for example, while admission is paused, then the function
+// is called on the calling goroutine.
+func (b *ErrorBudget) Execute(ctx context.Context, f func(context.Context)) {
+	if err := b.sem.acquireContext(ctx, 1); err != nil {
+		f(ctx)
+		return
+	}
+	go func() {
+		defer b.sem.release(1)
+		f(ctx)
+	}()
+}
+
+// record records the outcome of a worker and pauses
+// admission if the error rate exceeds the threshold.
+func (b *ErrorBudget) record(ctx context.Context, failed bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.paused {
+		return
+	}
+
+	if len(b.outcomes) < cap(b.outcomes) {
+		b.outcomes = append(b.outcomes, failed)
+	} else {
+		if b.outcomes[b.next] {
+			b.nerrors--
+		}
+		b.outcomes[b.next] = failed
+		b.next = (b.next + 1) % len(b.outcomes)
+	}
+	if failed {
+		b.nerrors++
+		b.nsuccess = 0
+	} else {
+		b.nsuccess++
+	}
+
+	if len(b.outcomes) == cap(b.outcomes) && float64(b.nerrors)/float64(len(b.outcomes)) > b.threshold {
+		b.pause(ClockFrom(ctx))
+		return
+	}
+
+	if b.nsuccess >= cap(b.outcomes) && b.limit < b.max {
+		b.nsuccess = 0
+		b.limit++
+		b.sem.resize(b.limit)
+	}
+}
+
+// pause pauses admission for the cooldown, the mutex must be held.
+func (b *ErrorBudget) pause(clock Clock) {
+	b.paused = true
+	b.sem.resize(0)
+
+	go func() {
+		sleep(context.Background(), clock, b.cooldown)
+
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		b.paused = false
+		b.limit /= 2
+		if b.limit < 1 {
+			b.limit = 1
+		}
+		b.outcomes = b.outcomes[:0]
+		b.next = 0
+		b.nerrors = 0
+		b.nsuccess = 0
+		b.sem.resize(b.limit)
+	}()
+}
+
+type budgetManager struct {
+	b *ErrorBudget
+	m Manager
+}
+
+// Manager wraps a Manager, m, and reports the outcome of each worker to
+// the error budget. If manager, m, is not provided then the manager
+// provided by CancelNeverFirstError is used, so that errors degrade the
+// work group rather than cancel it.
+func (b *ErrorBudget) Manager(m Manager) Manager {
+	if m == nil {
+		m = CancelNeverFirstError()
+	}
+	return &budgetManager{b: b, m: m}
+}
+
+func (m *budgetManager) Error() error {
+	return m.m.Error()
+}
+
+func (m *budgetManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := m.m.Manage(ctx, c, idx, err)
+	m.b.record(ctx, *err != nil)
+	return n
+}
@@ -0,0 +1,41 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestErrorBudget(t *testing.T) {
+
+	b := NewErrorBudget(4, 0.5, 4, time.Hour)
+
+	var count int32
+	err := WorkFor(context.Background(), b, b.Manager(nil), 4, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&count, 1)
+		return errors.New("failed")
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Fatalf("Expecting worker error, got %v", err)
+	}
+	if count != 4 {
+		t.Fatalf("Expecting 4 workers executed, got %d", count)
+	}
+	if !b.Paused() || b.Limit() != 4 {
+		t.Fatalf("Expecting admission paused at the limit of 4, got %d", b.Limit())
+	}
+
+	// the workers of a cancelled group are not held while paused
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	count = 0
+	WorkFor(ctx, b, b.Manager(nil), 3, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&count, 1)
+		return ctx.Err()
+	})
+	if count != 3 {
+		t.Fatalf("Expecting 3 workers executed while paused, got %d", count)
+	}
+}
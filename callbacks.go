@@ -0,0 +1,54 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+)
+
+type finishKey struct{}
+
+type firstErrorKey struct{}
+
+// OnFinish returns a copy of the context, ctx, that configures the work
+// group started with it to call the function, fn, on a new goroutine with
+// the report of the run, see Report, when the work group has completed.
+// It attaches notification side effects, such as alerts or finalizing
+// metrics, to a work group declaratively. Work groups nested in the
+// group do not call the function.
+func OnFinish(ctx context.Context, fn func(Report)) context.Context {
+	return context.WithValue(ctx, finishKey{}, fn)
+}
+
+// OnFirstError returns a copy of the context, ctx, that configures the
+// work group started with it to call the function, fn, on a new goroutine
+// with the error of the first worker of the group to complete with an
+// error. The error is the error of the worker after it is handled by the
+// manager of the group. Work groups nested in the group do not call the
+// function.
+func OnFirstError(ctx context.Context, fn func(error)) context.Context {
+	return context.WithValue(ctx, firstErrorKey{}, fn)
+}
+
+// firstErrorHook calls the function configured by OnFirstError
+// once, for the first worker that completes with an error.
+type firstErrorHook struct {
+	once sync.Once
+	fn   func(error)
+}
+
+func newFirstErrorHook(ctx context.Context) *firstErrorHook {
+	fn, _ := ctx.Value(firstErrorKey{}).(func(error))
+	if fn == nil {
+		return nil
+	}
+	return &firstErrorHook{fn: fn}
+}
+
+func (h *firstErrorHook) complete(err error) {
+	if err == nil {
+		return
+	}
+	h.once.Do(func() {
+		go h.fn(err)
+	})
+}
@@ -0,0 +1,41 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCompletionCallbacks(t *testing.T) {
+
+	finished := make(chan Report, 1)
+	firstError := make(chan error, 2)
+
+	failed := errors.New("failed")
+	ctx := OnFinish(context.Background(), func(r Report) {
+		finished <- r
+	})
+	ctx = OnFirstError(ctx, func(err error) {
+		firstError <- err
+	})
+
+	err := WorkFor(ctx, nil, CancelNeverFirstError(), 4, func(ctx context.Context, index int) error {
+		if index%2 == 1 {
+			return failed
+		}
+		return nil
+	})
+	if err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+
+	if r := <-finished; r.Err != failed || r.Workers != 4 || r.Failed != 2 {
+		t.Fatalf("Unexpected finish report: %+v", r)
+	}
+	if err := <-firstError; err != failed {
+		t.Fatalf("Expecting first error %v, got %v", failed, err)
+	}
+	if len(firstError) != 0 {
+		t.Fatalf("First error callback called more than once")
+	}
+}
@@ -0,0 +1,42 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+// Check returns the error of the context, ctx, if it is done, otherwise
+// nil. It is a shorthand for workers that poll for cancellation between
+// steps, instead of a select statement with a default case.
+func Check(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// Sleep pauses for the duration, d, or until the context, ctx, is done,
+// in which case the error of the context is returned. The time is
+// measured by the clock of the context, see WithClock.
+func Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, ClockFrom(ctx), d)
+}
+
+// Every returns a function for loops of workers that returns the error
+// of the context, ctx, if it is done, but only checks the context on
+// every n-th call, so that a tight loop polls for cancellation cheaply.
+// If n <= 1, then the context is checked on every call. The function is
+// not safe for concurrent use.
+func Every(ctx context.Context, n int) func() error {
+	i := 0
+	return func() error {
+		i++
+		if i < n {
+			return nil
+		}
+		i = 0
+		return Check(ctx)
+	}
+}
@@ -0,0 +1,37 @@
+package workgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := Check(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	check := Every(ctx, 10)
+	cancel()
+	if err := Check(ctx); err != context.Canceled {
+		t.Fatalf("Expecting context canceled, got %v", err)
+	}
+
+	calls := 1
+	for check() == nil {
+		calls++
+	}
+	if calls != 10 {
+		t.Fatalf("Expecting cancellation on the 10th call, got %d", calls)
+	}
+
+	start := time.Now()
+	if err := Sleep(ctx, time.Second); err != context.Canceled || time.Since(start) > 100*time.Millisecond {
+		t.Fatalf("Expecting sleep interrupted by cancellation, got %v", err)
+	}
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
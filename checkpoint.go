@@ -0,0 +1,162 @@
+package workgroup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+)
+
+type checkpointKey struct{}
+
+// ErrInvalidChunkSize is returned by WorkForChunked if the chunk size is not
+// positive and the context is configured by WithCheckpoint, because the
+// default chunk size depends on the machine.
+var ErrInvalidChunkSize = errors.New("workgroup: invalid chunk size")
+
+// CheckpointStore stores the indexes of the workers of WorkFor that have
+// completed successfully, so that a work group that is interrupted, for
+// example by a restart of the process, can be resumed, see WithCheckpoint.
+type CheckpointStore interface {
+	// Save records that the worker with the index has completed.
+	Save(ctx context.Context, index int) error
+
+	// Load returns the indexes of the workers that have completed.
+	Load(ctx context.Context) ([]int, error)
+}
+
+// WithCheckpoint returns a copy of the context, ctx, that configures the
+// work group started with it by WorkFor, or WorkForChunked, to resume from
+// the checkpoint store, s. The workers whose indexes are loaded from the
+// store are neither invoked nor passed to the manager, and the index of
+// each worker that completes without an error is saved to the store. If
+// the indexes can not be loaded, then no worker is invoked and the error
+// is returned. If an index can not be saved, then the error is the error
+// of the worker. Work groups nested in the group are not checkpointed.
+// The indexes of WorkForChunked are the indexes of the chunks, so that
+// the chunk size must be the same when the group is resumed, and it must
+// be provided explicitly, see ErrInvalidChunkSize.
+func WithCheckpoint(ctx context.Context, s CheckpointStore) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, s)
+}
+
+// checkpointed returns the worker, w, that saves its index to the store
+// of the group, and the indexes that are completed, if the group is
+// configured by WithCheckpoint.
+func (g *group) checkpointed(w IdxWorker) (IdxWorker, map[int]bool, error) {
+	if g.checkpoint == nil {
+		return w, nil, nil
+	}
+	indexes, err := g.checkpoint.Load(g.ctx)
+	if err != nil {
+		return w, nil, err
+	}
+	completed := make(map[int]bool, len(indexes))
+	for _, index := range indexes {
+		completed[index] = true
+	}
+	s := g.checkpoint
+	return func(ctx context.Context, index int) error {
+		err := w(ctx, index)
+		if err == nil {
+			err = s.Save(ctx, index)
+		}
+		return err
+	}, completed, nil
+}
+
+// FileCheckpoint is a checkpoint store that appends the
+// indexes of completed workers to a file, see WithCheckpoint.
+type FileCheckpoint struct {
+	path  string
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFileCheckpoint initializes a new checkpoint store with the file,
+// path, which is created when the first index is saved. The store must
+// be closed when the work group has completed, see Close.
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+// Save appends the index to the file.
+func (f *FileCheckpoint) Save(ctx context.Context, index int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return err
+		}
+		if err = truncateLine(file); err != nil {
+			file.Close()
+			return err
+		}
+		f.file = file
+	}
+	_, err := f.file.WriteString(strconv.Itoa(index) + "\n")
+	return err
+}
+
+// truncateLine truncates the file after its last complete line, so
+// that an incomplete line, from an interrupted write, is not continued
+// by the next index, and positions the file at its end.
+func truncateLine(file *os.File) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	n := int64(bytes.LastIndexByte(data, '\n') + 1)
+	if n < int64(len(data)) {
+		if err = file.Truncate(n); err != nil {
+			return err
+		}
+	}
+	_, err = file.Seek(n, io.SeekStart)
+	return err
+}
+
+// Load reads the indexes from the file, the result is
+// empty if the file does not exist. An incomplete last
+// line, from an interrupted write, is ignored.
+func (f *FileCheckpoint) Load(ctx context.Context) ([]int, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+
+	var indexes []int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		index, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, scanner.Err()
+}
+
+// Close closes the file.
+func (f *FileCheckpoint) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,90 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+
+	failed := errors.New("failed")
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	var mutex sync.Mutex
+	var invoked []int
+	work := func(fail bool) error {
+		store := NewFileCheckpoint(path)
+		defer store.Close()
+		invoked = nil
+		ctx := WithCheckpoint(context.Background(), store)
+		return WorkFor(ctx, NewSerial(), CancelNeverFirstError(), 10, func(ctx context.Context, index int) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			invoked = append(invoked, index)
+			if fail && index%3 == 0 {
+				return failed
+			}
+			return nil
+		})
+	}
+
+	if err := work(true); err != failed || len(invoked) != 10 {
+		t.Fatalf("Expecting 10 workers invoked with failures, got %d: %v", len(invoked), err)
+	}
+
+	// the failed workers are resumed
+	if err := work(false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(invoked) != 4 || invoked[0] != 0 || invoked[3] != 9 {
+		t.Fatalf("Expecting the 4 failed workers resumed: %v", invoked)
+	}
+
+	if err := work(false); err != nil || len(invoked) != 0 {
+		t.Fatalf("Expecting no workers resumed, got %v: %v", invoked, err)
+	}
+}
+
+func TestFileCheckpointIncompleteLine(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	if err := os.WriteFile(path, []byte("3\n12"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFileCheckpoint(path)
+	indexes, err := store.Load(context.Background())
+	if err != nil || fmt.Sprint(indexes) != "[3]" {
+		t.Fatalf("Expecting the incomplete line ignored, got %v: %v", indexes, err)
+	}
+
+	if err := store.Save(context.Background(), 5); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	store.Close()
+	indexes, err = store.Load(context.Background())
+	if err != nil || fmt.Sprint(indexes) != "[3 5]" {
+		t.Fatalf("Expecting the incomplete line truncated, got %v: %v", indexes, err)
+	}
+}
+
+func TestCheckpointChunkSize(t *testing.T) {
+
+	store := NewFileCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+	defer store.Close()
+
+	var invoked bool
+	ctx := WithCheckpoint(context.Background(), store)
+	err := WorkForChunked(ctx, nil, nil, 100, 0, func(ctx context.Context, start, end int) error {
+		invoked = true
+		return nil
+	})
+	if err != ErrInvalidChunkSize || invoked {
+		t.Fatalf("Expecting error %v, got %v", ErrInvalidChunkSize, err)
+	}
+}
@@ -0,0 +1,54 @@
+package workgroup
+
+import "context"
+
+// Severity is the classification of the error of a worker, see WithClassifier.
+type Severity int
+
+const (
+	// SeverityDefault is handled by the manager as any other error.
+	SeverityDefault Severity = iota
+
+	// SeverityFatal is counted as a failure and cancels the work group
+	// immediately, including with CancelOnFirstSuccess and
+	// CancelNeverFirstError, which do not otherwise cancel on an error.
+	SeverityFatal
+
+	// SeverityRecorded is counted as a failure, and may become the error
+	// of the work group, but never cancels the work group.
+	SeverityRecorded
+
+	// SeverityRetryable is retried by the RetryIf wrapper, and, when it
+	// is still returned after the retries, it is handled as SeverityRecorded.
+	SeverityRetryable
+
+	// SeverityIgnorable is not counted as a failure, it is neutral in the
+	// same way as the errors of a cancelled work group, see IgnoreGroupCancel.
+	SeverityIgnorable
+)
+
+// WithClassifier configures the built-in managers to consult the function,
+// classify, for the severity of the error of each worker that completes with
+// an error. For example, validation errors can be recorded without cancelling
+// the work group, while authorization errors cancel it immediately. Errors
+// that are neutral by the IgnoreGroupCancel option are not classified.
+func WithClassifier(classify func(error) Severity) ManagerOption {
+	return func(o *managerOptions) {
+		o.classify = classify
+	}
+}
+
+// severity returns the severity of the error, err,
+// of a worker of the work group with context, ctx.
+func (o *managerOptions) severity(ctx context.Context, err error) Severity {
+	if err == nil {
+		return SeverityDefault
+	}
+	if o.neutral(ctx, err) {
+		return SeverityIgnorable
+	}
+	if o.classify == nil {
+		return SeverityDefault
+	}
+	return o.classify(err)
+}
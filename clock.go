@@ -0,0 +1,143 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type clockKey struct{}
+
+// Clock provides the time to the time-based components of the package,
+// such as the rate-limited, adaptive and pool executers, so that tests
+// can control the passage of time, see the workgrouptest package.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a timer that sends the current
+	// time on its channel after the duration, d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer created by a Clock.
+type Timer interface {
+	// C returns the channel on which the time is sent.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing and reports
+	// whether the timer was stopped before it fired.
+	Stop() bool
+}
+
+// WithClock returns a copy of the context, ctx, that configures the
+// time-based components used with the context to use the clock, c.
+// Executers, such as the pool executer, that are constructed with a
+// context use the clock of that context, otherwise the clock of the
+// context that functions are executed with is used.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// ClockFrom returns the clock configured for the context, ctx,
+// by WithClock, or a clock that provides the system time.
+func ClockFrom(ctx context.Context) Clock {
+	if ctx != nil {
+		if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+			return c
+		}
+	}
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// sleep pauses for the duration, d, measured by
+// the clock, c, or until the context, ctx, is done.
+func sleep(ctx context.Context, c Clock, d time.Duration) error {
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withTimeout returns a copy of the context, ctx, that is done with the
+// error context.DeadlineExceeded once the duration, d, measured by the
+// clock, c, has elapsed, and the function that cancels it.
+func withTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := c.(systemClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+
+	tctx := &timeoutContext{Context: ctx, deadline: c.Now().Add(d), done: make(chan struct{})}
+	timer := c.NewTimer(d)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+			tctx.cancel(context.DeadlineExceeded)
+		case <-ctx.Done():
+			tctx.cancel(ctx.Err())
+		case <-tctx.done:
+		}
+	}()
+	return tctx, func() { tctx.cancel(context.Canceled) }
+}
+
+// timeoutContext is a context with a deadline measured by a clock.
+type timeoutContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	once     sync.Once
+	err      error
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timeoutContext) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+func (c *timeoutContext) cancel(err error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
@@ -0,0 +1,121 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Coalescer collapses bursts of submissions of the same work, by key, into
+// a single execution, for example, to refresh a cache once after it has been
+// invalidated hundreds of times. The executions are the workers of a single
+// work group, see NewCoalescer.
+type Coalescer struct {
+	h     *Handle
+	clock Clock
+	quiet time.Duration
+	max   time.Duration
+
+	mutex   sync.Mutex
+	wg      sync.WaitGroup
+	pending map[string]*coalesced
+	closed  bool
+	flush   chan struct{}
+}
+
+type coalesced struct {
+	w     Worker
+	first time.Time
+	reset chan struct{}
+}
+
+// NewCoalescer starts a new work group to which the coalesced work is added
+// when no work with the same key has been submitted for the quiet period,
+// quiet, or when the first submission has been pending for the delay, max,
+// whichever is first. If max <= 0, then the work is only added after the
+// quiet period. The executer, e, and the manager, m, are used as for Work().
+// The time is measured by the clock of the context, see WithClock.
+func NewCoalescer(ctx context.Context, e Executer, m Manager, quiet, max time.Duration) *Coalescer {
+	return &Coalescer{
+		h:       NewHandle(ctx, e, m),
+		clock:   ClockFrom(ctx),
+		quiet:   quiet,
+		max:     max,
+		pending: make(map[string]*coalesced),
+		flush:   make(chan struct{}),
+	}
+}
+
+// Submit submits the worker, w, with the key. If work with the same key is
+// pending, then the worker replaces the pending worker, so that the last
+// worker that is submitted in a burst is executed. The worker is rejected
+// with ErrClosed if the coalescer has been closed.
+func (c *Coalescer) Submit(key string, w Worker) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	if p, ok := c.pending[key]; ok {
+		p.w = w
+		select {
+		case p.reset <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	p := &coalesced{w: w, first: c.clock.Now(), reset: make(chan struct{}, 1)}
+	c.pending[key] = p
+	c.wg.Add(1)
+	go c.wait(key, p)
+	return nil
+}
+
+// wait waits for the pending work, p, with the key to be due.
+func (c *Coalescer) wait(key string, p *coalesced) {
+	defer c.wg.Done()
+	for {
+		d := c.quiet
+		if c.max > 0 {
+			if rem := c.max - c.clock.Now().Sub(p.first); rem < d {
+				d = rem
+			}
+		}
+		timer := c.clock.NewTimer(d)
+		select {
+		case <-timer.C():
+		case <-p.reset:
+			timer.Stop()
+			continue
+		case <-c.flush:
+			timer.Stop()
+		}
+		break
+	}
+
+	c.mutex.Lock()
+	delete(c.pending, key)
+	w := p.w
+	c.mutex.Unlock()
+
+	// the work is dropped if the group is cancelled
+	c.h.Add(w)
+}
+
+// Close executes the pending work immediately, rejects further
+// submissions, and waits for the executions to complete. The error
+// of the work group provided by the manager is returned.
+func (c *Coalescer) Close() error {
+	c.mutex.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.flush)
+	}
+	c.mutex.Unlock()
+
+	c.wg.Wait()
+	c.h.Close()
+	return c.h.Wait()
+}
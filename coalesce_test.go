@@ -0,0 +1,63 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalescer(t *testing.T) {
+
+	var mutex sync.Mutex
+	runs := make(map[string][]int)
+	work := func(key string, i int) Worker {
+		return func(ctx context.Context) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			runs[key] = append(runs[key], i)
+			return nil
+		}
+	}
+
+	c := NewCoalescer(context.Background(), nil, nil, 10*time.Millisecond, 0)
+	for i := 0; i < 100; i++ {
+		c.Submit("a", work("a", i))
+		c.Submit("b", work("b", i))
+	}
+	time.Sleep(50 * time.Millisecond)
+	c.Submit("a", work("a", 100))
+	if err := c.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := c.Submit("a", work("a", 101)); err != ErrClosed {
+		t.Fatalf("Expecting submission rejected after close: %v", err)
+	}
+
+	if a := runs["a"]; len(a) != 2 || a[0] != 99 || a[1] != 100 {
+		t.Fatalf("Expecting 2 coalesced runs of the last submissions: %v", a)
+	}
+	if b := runs["b"]; len(b) != 1 || b[0] != 99 {
+		t.Fatalf("Expecting 1 coalesced run of the last submission: %v", b)
+	}
+}
+
+func TestCoalescerMaxDelay(t *testing.T) {
+
+	var mutex sync.Mutex
+	runs := 0
+	c := NewCoalescer(context.Background(), nil, nil, 10*time.Millisecond, 30*time.Millisecond)
+	for i := 0; i < 20; i++ {
+		c.Submit("a", func(ctx context.Context) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			runs++
+			return nil
+		})
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.Close()
+	if runs < 2 {
+		t.Fatalf("Expecting the max delay to bound a continuous burst, got %d runs", runs)
+	}
+}
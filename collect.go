@@ -0,0 +1,54 @@
+package workgroup
+
+import "context"
+
+// WorkChanCollect arranges for the group of functions provided by the
+// channel, in, to be executed, like WorkChan, and fans the results of
+// the functions into the results channel. The error of the work group,
+// if any, is sent on the error channel once all of the functions have
+// completed, then both channels are closed. The result of a function
+// that returns an error is discarded. The results channel must be
+// drained, unless the context, ctx, is cancelled, in which case pending
+// results are discarded. The results are sent in the order that the
+// functions are received if configured by OrderedResults. The functions
+// are indexed from one, in the order that they are received, like the
+// workers of WorkChan. See documention for WorkChan() for details.
+func WorkChanCollect[T any](ctx context.Context, e Executer, m Manager, in <-chan func(context.Context) (T, error)) (<-chan T, <-chan error) {
+	results := make(chan T)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+
+		if ctx == nil {
+			ctx = context.TODO()
+		}
+		grp := newGroup(ctx, e, m)
+		defer grp.close()
+
+		grp.first = 1
+		emit := newEmitter[T](ctx, results, grp.first)
+
+		for i := grp.first; ; i++ {
+			f, ok := receive(grp, in)
+			if !ok {
+				break
+			}
+			index := i
+			grp.execute(index, func(ctx context.Context) error {
+				v, err := f(ctx)
+				emit.emit(index, v, err == nil)
+				return err
+			})
+		}
+
+		err := grp.wait()
+		emit.close()
+		close(results)
+		if err != nil {
+			errc <- err
+		}
+	}()
+
+	return results, errc
+}
@@ -0,0 +1,123 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestWorkChanCollect(t *testing.T) {
+
+	in := make(chan func(context.Context) (int, error))
+	go func() {
+		defer close(in)
+		for i := 1; i <= 10; i++ {
+			n := i
+			in <- func(ctx context.Context) (int, error) {
+				return n * n, nil
+			}
+		}
+	}()
+
+	results, errc := WorkChanCollect(context.Background(), nil, nil, in)
+
+	sum := 0
+	for v := range results {
+		sum += v
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum != 385 {
+		t.Fatalf("Expecting sum of results 385, got %d", sum)
+	}
+}
+
+func TestWorkChanCollectError(t *testing.T) {
+
+	failed := errors.New("failed")
+	in := make(chan func(context.Context) (string, error), 2)
+	in <- func(ctx context.Context) (string, error) {
+		return "", failed
+	}
+	in <- func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "cancelled", nil
+	}
+	close(in)
+
+	results, errc := WorkChanCollect(context.Background(), nil, nil, in)
+
+	for range results {
+	}
+	if err := <-errc; err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+}
+
+func TestWorkChanCollectOrdered(t *testing.T) {
+
+	in := make(chan func(context.Context) (int, error))
+	go func() {
+		defer close(in)
+		for i := 0; i < 10; i++ {
+			n := i
+			in <- func(ctx context.Context) (int, error) {
+				if n%3 == 0 {
+					return 0, errors.New("failed")
+				}
+				time.Sleep(time.Duration(10-n) * time.Millisecond)
+				return n, nil
+			}
+		}
+	}()
+
+	ctx := OrderedResults(context.Background())
+	results, errc := WorkChanCollect(ctx, nil, CancelNeverFirstError(), in)
+
+	var got []int
+	for v := range results {
+		got = append(got, v)
+	}
+	<-errc
+	want := []int{1, 2, 4, 5, 7, 8}
+	if len(got) != len(want) {
+		t.Fatalf("Expecting results %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expecting results %v, got %v", want, got)
+		}
+	}
+}
+
+func TestWorkChanCollectIndex(t *testing.T) {
+
+	in := make(chan func(context.Context) (int, error))
+	m := &indexManager{Manager: CancelNeverFirstError()}
+	ctx := OrderedResults(context.Background())
+	results, errc := WorkChanCollect(ctx, nil, m, in)
+
+	// the ordered results are sent while the channel is open
+	for i := 0; i < 3; i++ {
+		n := i
+		in <- func(ctx context.Context) (int, error) {
+			return n, nil
+		}
+		if v := <-results; v != n {
+			t.Fatalf("Expecting result %d, got %d", n, v)
+		}
+	}
+	close(in)
+	for range results {
+	}
+	<-errc
+
+	sort.Ints(m.indexes)
+	if fmt.Sprint(m.indexes) != "[1 2 3]" {
+		t.Fatalf("Expecting functions indexed from one, got %v", m.indexes)
+	}
+}
@@ -0,0 +1,102 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type committer struct {
+	m      Manager
+	n      int
+	d      time.Duration
+	commit func(offset int)
+
+	mutex     sync.Mutex
+	started   bool
+	done      map[int]bool
+	next      int
+	committed int
+	last      time.Time
+}
+
+// CommitEvery wraps a Manager, m, to coordinate the commit of the
+// offsets of an at-least-once source, such as a stream consumer that
+// provides its items to WorkChan. The offset of an item is the index
+// of its worker, so the first offset is the index of the first worker
+// of the group, which is one for WorkChan. The committer tracks the
+// workers that complete without error and calls the function, commit,
+// with the highest offset such that the workers of that offset and all
+// preceding offsets have completed. The commit is made when the offset
+// has advanced by at least n since the last commit, or the offset has
+// advanced and at least the duration, d, has elapsed since the last
+// commit, or the first completion, as measured by the clock of the
+// worker context. If n <= 0 or d <= 0 then the respective condition is
+// not used. The remaining completed offsets are committed when the work
+// group completes. The worker of a failed item is never committed, so
+// the commits do not advance past it. The function is called while the
+// manager is locked. If manager, m, is not provided then DefaultManager
+// is called to obtain the default manager.
+func CommitEvery(n int, d time.Duration, commit func(offset int), m Manager) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &committer{
+		m:         m,
+		n:         n,
+		d:         d,
+		commit:    commit,
+		done:      make(map[int]bool),
+		committed: -1,
+	}
+}
+
+func (c *committer) Error() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.next-1 > c.committed {
+		c.flush(c.last)
+	}
+	return c.m.Error()
+}
+
+func (c *committer) Manage(ctx context.Context, cn Canceller, idx int, err *error) int {
+	n := c.m.Manage(ctx, cn, idx, err)
+	if *err != nil {
+		return n
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	clock := ClockFrom(ctx)
+	if !c.started {
+		// the offsets start at the index of the first worker
+		c.started = true
+		c.next = firstIndex(ctx)
+		c.committed = c.next - 1
+		c.last = clock.Now()
+	}
+
+	c.done[idx] = true
+	for c.done[c.next] {
+		delete(c.done, c.next)
+		c.next++
+	}
+
+	offset := c.next - 1
+	if offset <= c.committed {
+		return n
+	}
+	if (c.n > 0 && offset-c.committed >= c.n) || (c.d > 0 && clock.Now().Sub(c.last) >= c.d) {
+		c.flush(clock.Now())
+	}
+	return n
+}
+
+// flush commits the highest contiguous completed offset at the time, now.
+func (c *committer) flush(now time.Time) {
+	c.committed = c.next - 1
+	c.last = now
+	c.commit(c.committed)
+}
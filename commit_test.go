@@ -0,0 +1,86 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCommitEvery(t *testing.T) {
+
+	var commits []int
+	m := CommitEvery(3, 0, func(offset int) {
+		commits = append(commits, offset)
+	}, nil)
+
+	WorkFor(context.Background(), nil, m, 10, func(ctx context.Context, index int) error {
+		time.Sleep(time.Duration(10-index) * time.Millisecond)
+		return nil
+	})
+
+	if len(commits) == 0 || commits[len(commits)-1] != 9 {
+		t.Fatalf("Expecting final commit of offset 9, got %v", commits)
+	}
+	for i := 1; i < len(commits); i++ {
+		if commits[i] <= commits[i-1] {
+			t.Fatalf("Commits are not increasing: %v", commits)
+		}
+	}
+}
+
+func TestCommitEveryOffsets(t *testing.T) {
+
+	var commits []int
+	m := CommitEvery(4, time.Hour, func(offset int) {
+		commits = append(commits, offset)
+	}, CancelNeverFirstError())
+
+	c := CancellerFunc(func() {})
+	ctx := context.Background()
+	manage := func(idx int, err error) {
+		m.Manage(ctx, c, idx, &err)
+	}
+
+	manage(1, nil)
+	manage(2, nil)
+	manage(3, nil)
+	if len(commits) != 0 {
+		t.Fatalf("Unexpected commit before offset 0 completed: %v", commits)
+	}
+	manage(0, nil)
+	manage(5, nil)
+	manage(4, errors.New("failed"))
+	manage(6, nil)
+	manage(7, nil)
+	manage(8, nil)
+	manage(9, nil)
+
+	if err := m.Error(); err == nil {
+		t.Fatalf("Expecting error from failed worker")
+	}
+	if len(commits) != 1 || commits[0] != 3 {
+		t.Fatalf("Expecting single commit of offset 3, got %v", commits)
+	}
+}
+
+func TestCommitEveryWorkChan(t *testing.T) {
+
+	var commits []int
+	m := CommitEvery(2, 0, func(offset int) {
+		commits = append(commits, offset)
+	}, nil)
+
+	workers := make(chan Worker, 5)
+	for i := 0; i < 5; i++ {
+		workers <- func(ctx context.Context) error {
+			return nil
+		}
+	}
+	close(workers)
+	WorkChan(context.Background(), NewSerial(), m, workers)
+
+	if len(commits) != 3 || commits[0] != 2 || commits[1] != 4 || commits[2] != 5 {
+		t.Fatalf("Expecting commits of offsets 2, 4 and 5, got %v", commits)
+	}
+}
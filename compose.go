@@ -0,0 +1,104 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type chain struct {
+	ms        []Manager
+	ncomplete int64
+	cancelled int32 // index of the first manager to cancel, plus one
+}
+
+// Chain returns a manager that passes every completed worker to each of
+// the managers, ms, in order, so that behaviors can be composed, such as
+// accumulating the errors of all workers, cancelling on the first fatal
+// error, see Filter, and reporting progress. The work group is cancelled
+// when any of the managers cancels it, and the error of the work group is
+// the error of the first manager to cancel, or otherwise the first error
+// of the managers in order. Note that the Recover and Repanic wrappers
+// must be the outermost manager, so they should wrap this manager and not
+// be chained.
+func Chain(ms ...Manager) Manager {
+	return &chain{ms: ms}
+}
+
+// NeverCancels reports whether none of the chained managers cancels.
+func (m *chain) NeverCancels() bool {
+	for _, c := range m.ms {
+		if !neverCancels(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *chain) Error() error {
+	if i := atomic.LoadInt32(&m.cancelled); i > 0 {
+		if err := m.ms[i-1].Error(); err != nil {
+			return err
+		}
+	}
+	for _, c := range m.ms {
+		if err := c.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *chain) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	for i, cm := range m.ms {
+		cm.Manage(ctx, &chainCanceller{m: m, c: c, i: int32(i + 1)}, idx, err)
+	}
+	return int(atomic.AddInt64(&m.ncomplete, 1))
+}
+
+// chainCanceller records the first of the
+// chained managers to cancel the work group.
+type chainCanceller struct {
+	m *chain
+	c Canceller
+	i int32
+}
+
+func (c *chainCanceller) Cancel() {
+	atomic.CompareAndSwapInt32(&c.m.cancelled, 0, c.i)
+	c.c.Cancel()
+}
+
+type filter struct {
+	pred      func(idx int, err error) bool
+	m         Manager
+	ncomplete int64
+}
+
+// Filter wraps a Manager, m, and passes it only the completed workers for
+// which the function, pred, returns true, given the index and the error
+// of the worker. For example, the manager provided by CancelOnFirstError
+// can be filtered to cancel only on the errors that are fatal, and chained
+// with other managers, see Chain. If manager, m, is not provided then
+// DefaultManager is called to obtain the default manager.
+func Filter(pred func(idx int, err error) bool, m Manager) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &filter{pred: pred, m: m}
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (f *filter) NeverCancels() bool {
+	return neverCancels(f.m)
+}
+
+func (f *filter) Error() error {
+	return f.m.Error()
+}
+
+func (f *filter) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	if f.pred(idx, *err) {
+		f.m.Manage(ctx, c, idx, err)
+	}
+	return int(atomic.AddInt64(&f.ncomplete, 1))
+}
@@ -0,0 +1,65 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+
+	errFatal := errors.New("fatal")
+	errMinor := errors.New("minor")
+
+	var completed int32
+	a := Accumulate(CancelNeverFirstError())
+	m := Chain(
+		a,
+		Filter(func(idx int, err error) bool {
+			return errors.Is(err, errFatal)
+		}, CancelOnFirstError()),
+		Observe(CancelNeverFirstError(), Hooks{
+			OnComplete: func(idx int) {
+				atomic.AddInt32(&completed, 1)
+			},
+		}),
+	)
+
+	err := WorkFor(context.Background(), NewSerial(), m, 10, func(ctx context.Context, index int) error {
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case index == 5:
+			return errFatal
+		case index%2 == 1:
+			return errMinor
+		}
+		return nil
+	})
+
+	if err != errFatal {
+		t.Fatalf("Expecting the error of the cancelling manager %v, got %v", errFatal, err)
+	}
+	if completed != 10 {
+		t.Fatalf("Expecting 10 completions observed, got %d", completed)
+	}
+	if gerr := a.GroupError(); gerr == nil || gerr.ByIndex(1) != errMinor || gerr.ByIndex(9) != context.Canceled {
+		t.Fatalf("Expecting errors of all workers accumulated, got %v", gerr)
+	}
+
+	err = WorkFor(context.Background(), NewSerial(), Chain(CancelNeverFirstError(), Filter(func(idx int, err error) bool {
+		return errors.Is(err, errFatal)
+	}, CancelOnFirstError())), 3, func(ctx context.Context, index int) error {
+		if index == 0 {
+			return errMinor
+		}
+		return ctx.Err()
+	})
+	if err != errMinor {
+		t.Fatalf("Expecting the first error %v, got %v", errMinor, err)
+	}
+	if neverCancels(Chain(CancelNeverFirstError(), CancelOnFirstError())) {
+		t.Fatal("Expecting a chain with a cancelling manager to cancel")
+	}
+}
@@ -0,0 +1,30 @@
+package workgroup
+
+import "context"
+
+type defaultsKey struct{}
+
+// defaults are the executer and manager configured by NewContext.
+type defaults struct {
+	e Executer
+	m func() Manager
+}
+
+// NewContext returns a copy of the context, ctx, that configures the work
+// groups started with it, and all work groups nested within them, that are
+// not provided an executer to use the executer, e, and that are not provided
+// a manager to use a manager returned by the function, m, rather than the
+// package defaults, see DefaultExecuter and DefaultManager. An application
+// can impose a concurrency policy on libraries that use work groups with
+// the context. A manager holds the state of a single work group, so m must
+// return a new manager each time it is called. Either e or m may be nil.
+func NewContext(ctx context.Context, e Executer, m func() Manager) context.Context {
+	return context.WithValue(ctx, defaultsKey{}, &defaults{e: e, m: m})
+}
+
+func defaultsFrom(ctx context.Context) (Executer, func() Manager) {
+	if d, ok := ctx.Value(defaultsKey{}).(*defaults); ok {
+		return d.e, d.m
+	}
+	return nil, nil
+}
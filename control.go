@@ -0,0 +1,53 @@
+package workgroup
+
+import "context"
+
+// Controller is implemented by executers whose concurrency limit
+// can be adjusted while they are in use, such as the pool and the
+// adaptive executers.
+type Controller interface {
+	// Limit returns the current concurrency limit.
+	Limit() int
+
+	// SetLimit sets the concurrency limit to n.
+	SetLimit(n int)
+}
+
+// ControlFunc decides the concurrency limit from the error, err, of a
+// completed worker and the current limit. The result is the new limit.
+type ControlFunc func(err error, limit int) int
+
+type control struct {
+	m  Manager
+	c  Controller
+	fn ControlFunc
+}
+
+// Control wraps a Manager, m, and calls the function, fn, after the
+// wrapped manager has handled each completed worker, to adjust the
+// concurrency limit of the controller, c, usually the executer of the
+// work group. This closes the loop between the error policy and the
+// admission of workers, for example, to halve the concurrency when
+// workers are rate limited by a server. The function may be called
+// concurrently from multiple goroutines. If manager, m, is not provided
+// then DefaultManager is called to obtain the default manager.
+func Control(c Controller, m Manager, fn ControlFunc) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &control{m: m, c: c, fn: fn}
+}
+
+func (c *control) Error() error {
+	return c.m.Error()
+}
+
+func (c *control) Manage(ctx context.Context, cn Canceller, idx int, err *error) int {
+	n := c.m.Manage(ctx, cn, idx, err)
+
+	limit := c.c.Limit()
+	if l := c.fn(*err, limit); l != limit {
+		c.c.SetLimit(l)
+	}
+	return n
+}
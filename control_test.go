@@ -0,0 +1,45 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errTooManyRequests = errors.New("429 too many requests")
+
+func TestControl(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 8)
+	m := Control(p, CancelNeverFirstError(), func(err error, limit int) int {
+		if errors.Is(err, errTooManyRequests) && limit > 1 {
+			return limit / 2
+		}
+		return limit
+	})
+
+	WorkFor(ctx, p, m, 3, func(ctx context.Context, index int) error {
+		return errTooManyRequests
+	})
+
+	if n := p.Limit(); n != 1 {
+		t.Fatalf("Expecting pool limit halved to 1, got %d", n)
+	}
+}
+
+func TestControlAdaptive(t *testing.T) {
+
+	a := NewAdaptive(1, 10, 0)
+	a.SetLimit(20)
+	if n := a.Limit(); n != 10 {
+		t.Fatalf("Expecting limit clamped to 10, got %d", n)
+	}
+	var c Controller = a
+	c.SetLimit(4)
+	if n := c.Limit(); n != 4 {
+		t.Fatalf("Expecting limit of 4, got %d", n)
+	}
+}
@@ -0,0 +1,59 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// Deduper deduplicates concurrent calls with the same key, so that the
+// workers of a work group that request the same resource share a single
+// request to the backend. The zero value is not usable, see NewDeduper.
+type Deduper[T any] struct {
+	mutex sync.Mutex
+	calls map[string]*dedupeCall[T]
+}
+
+type dedupeCall[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// NewDeduper initializes a new deduper for results of type T.
+func NewDeduper[T any]() *Deduper[T] {
+	return &Deduper[T]{calls: make(map[string]*dedupeCall[T])}
+}
+
+// Do calls the function, fn, and returns its result, unless a call with
+// the same key is in progress, in which case Do waits for that call to
+// complete and returns the same result and error. The function is called
+// with the context of the first caller, so if that context is cancelled
+// then all of the callers may receive the error of the context. A caller
+// stops waiting when its context, ctx, is done and returns its error.
+// Calls that start after the function has returned call it again.
+func (d *Deduper[T]) Do(ctx context.Context, key string, fn func(context.Context) (T, error)) (T, error) {
+	d.mutex.Lock()
+	if c, ok := d.calls[key]; ok {
+		d.mutex.Unlock()
+		select {
+		case <-c.done:
+			return c.value, c.err
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+	c := &dedupeCall[T]{done: make(chan struct{})}
+	d.calls[key] = c
+	d.mutex.Unlock()
+
+	defer func() {
+		d.mutex.Lock()
+		delete(d.calls, key)
+		d.mutex.Unlock()
+		close(c.done)
+	}()
+
+	c.value, c.err = fn(ctx)
+	return c.value, c.err
+}
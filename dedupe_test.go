@@ -0,0 +1,55 @@
+package workgroup
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeduper(t *testing.T) {
+
+	d := NewDeduper[string]()
+
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(key string) func(context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return "value-" + key, nil
+		}
+	}
+
+	var entered int32
+	results := make([]string, 10)
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(context.Background(), nil, nil, 10, func(ctx context.Context, index int) error {
+			key := strconv.Itoa(index % 2)
+			atomic.AddInt32(&entered, 1)
+			v, err := d.Do(ctx, key, fetch(key))
+			results[index] = v
+			return err
+		})
+	}()
+
+	for atomic.LoadInt32(&entered) < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, v := range results {
+		if want := "value-" + strconv.Itoa(i%2); v != want {
+			t.Fatalf("Expecting result %q of worker %d, got %q", want, i, v)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("Expecting 2 calls, got %d", n)
+	}
+}
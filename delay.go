@@ -0,0 +1,62 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+// After returns a worker that waits for the duration, d, and then calls
+// the worker, w. If the context of the worker is done first, then the
+// worker, w, is not called and the error of the context is returned.
+// The time is measured by the clock of the context, see WithClock.
+func After(d time.Duration, w Worker) Worker {
+	return func(ctx context.Context) error {
+		if err := sleep(ctx, ClockFrom(ctx), d); err != nil {
+			return err
+		}
+		return w(ctx)
+	}
+}
+
+type delayed struct {
+	e     Executer
+	delay func(context.Context) time.Duration
+}
+
+// NewDelayed returns an executer that passes each function to the
+// executer, e, only after the delay returned by the function, delay,
+// for the context of the function, such as a delay by the index of the
+// worker for a staggered rollout, see IndexFromContext and Stagger. Unlike
+// a worker that sleeps, a delayed worker does not hold the capacity of the
+// executer, e, while it waits, and Execute does not block for the delay.
+// If the context is done before the delay has passed, then the function
+// is passed to the executer, e, immediately. If e is nil, then the value
+// provided by DefaultExecuter is used. The time is measured by the clock
+// of the context, see WithClock.
+func NewDelayed(e Executer, delay func(ctx context.Context) time.Duration) Executer {
+	if e == nil {
+		e = DefaultExecuter()
+	}
+	return &delayed{e: e, delay: delay}
+}
+
+// Stagger returns a delay for NewDelayed that delays each
+// worker by the interval, d, multiplied by its index.
+func Stagger(d time.Duration) func(context.Context) time.Duration {
+	return func(ctx context.Context) time.Duration {
+		index, _ := IndexFromContext(ctx)
+		return time.Duration(index) * d
+	}
+}
+
+func (d *delayed) Execute(ctx context.Context, f func(context.Context)) {
+	delay := d.delay(ctx)
+	if delay <= 0 {
+		d.e.Execute(ctx, f)
+		return
+	}
+	go func() {
+		sleep(ctx, ClockFrom(ctx), delay)
+		d.e.Execute(ctx, f)
+	}()
+}
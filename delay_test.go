@@ -0,0 +1,53 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAfter(t *testing.T) {
+
+	called := false
+	start := time.Now()
+	err := Work(context.Background(), nil, nil, After(10*time.Millisecond, func(ctx context.Context) error {
+		called = true
+		return nil
+	}))
+	if err != nil || !called || time.Since(start) < 10*time.Millisecond {
+		t.Fatalf("Expecting worker called after the delay: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	called = false
+	err = Work(ctx, nil, nil, After(time.Second, func(ctx context.Context) error {
+		called = true
+		return nil
+	}))
+	if !errors.Is(err, context.DeadlineExceeded) || called {
+		t.Fatalf("Expecting worker not called after cancellation: %v", err)
+	}
+}
+
+func TestDelayed(t *testing.T) {
+
+	var mutex sync.Mutex
+	var order []int
+	start := time.Now()
+	e := NewDelayed(NewLimited(1), Stagger(5*time.Millisecond))
+	WorkFor(context.Background(), e, nil, 4, func(ctx context.Context, index int) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, index)
+		return nil
+	})
+	if len(order) != 4 || order[0] != 0 || order[3] != 3 {
+		t.Fatalf("Expecting workers started in staggered order: %v", order)
+	}
+	if time.Since(start) < 15*time.Millisecond {
+		t.Fatalf("Expecting the last worker delayed by 15ms")
+	}
+}
@@ -0,0 +1,130 @@
+package workgroup
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DurationStats contains statistics of the durations of workers,
+// see StatsCollector. Workers that were not invoked are not included.
+type DurationStats struct {
+	Count int
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// statsSample is the number of durations sampled by a StatsCollector.
+const statsSample = 1024
+
+// StatsCollector records the duration of each worker of the work groups
+// that are managed by its wrapped managers, which is useful for tuning the
+// limits of executers empirically. The count, minimum, mean and maximum are
+// exact, the percentiles are estimated from a uniform sample of at most
+// 1024 durations, so that the memory of the collector is bounded.
+type StatsCollector struct {
+	mutex     sync.Mutex
+	count     int
+	sum       time.Duration
+	min       time.Duration
+	max       time.Duration
+	durations []time.Duration
+	rand      *rand.Rand
+}
+
+// CollectStats initializes a new collector of the durations of workers.
+func CollectStats() *StatsCollector {
+	return &StatsCollector{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Wrap wraps a Manager, m, to record the duration of each worker in the
+// collector. If manager, m, is not provided then DefaultManager is called
+// to obtain the default manager. The Recover and Repanic wrappers must
+// be the outermost manager, so they should wrap this manager.
+func (c *StatsCollector) Wrap(m Manager) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &statsManager{m: m, c: c}
+}
+
+// Stats returns the statistics of the durations of the workers that have
+// completed so far, it may be called while work groups are running.
+func (c *StatsCollector) Stats() DurationStats {
+	c.mutex.Lock()
+	s := DurationStats{Count: c.count, Min: c.min, Max: c.max}
+	if c.count > 0 {
+		s.Mean = c.sum / time.Duration(c.count)
+	}
+	durations := make([]time.Duration, len(c.durations))
+	copy(durations, c.durations)
+	c.mutex.Unlock()
+
+	if len(durations) == 0 {
+		return s
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	at := func(p int) time.Duration {
+		return durations[(len(durations)-1)*p/100]
+	}
+	s.P50, s.P95, s.P99 = at(50), at(95), at(99)
+	return s
+}
+
+// record records the duration, d, the sample is maintained
+// by reservoir sampling, each duration is kept with equal
+// probability.
+func (c *StatsCollector) record(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.count++
+	c.sum += d
+	if c.count == 1 || d < c.min {
+		c.min = d
+	}
+	if d > c.max {
+		c.max = d
+	}
+	if len(c.durations) < statsSample {
+		c.durations = append(c.durations, d)
+	} else if i := c.rand.Intn(c.count); i < statsSample {
+		c.durations[i] = d
+	}
+}
+
+type statsManager struct {
+	m Manager
+	c *StatsCollector
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (m *statsManager) NeverCancels() bool {
+	return neverCancels(m.m)
+}
+
+func (m *statsManager) Error() error {
+	return m.m.Error()
+}
+
+func (m *statsManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	return m.m.Manage(ctx, c, idx, err)
+}
+
+func (m *statsManager) ManageInfo(ctx context.Context, c Canceller, info WorkerInfo, err *error) int {
+	if !info.Started.IsZero() {
+		m.c.record(info.Elapsed)
+	}
+	if im, ok := m.m.(InfoManager); ok {
+		return im.ManageInfo(ctx, c, info, err)
+	}
+	return m.m.Manage(ctx, c, info.Index, err)
+}
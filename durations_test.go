@@ -0,0 +1,52 @@
+package workgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCollectStats(t *testing.T) {
+
+	stats := CollectStats()
+	err := WorkFor(context.Background(), nil, Recover(stats.Wrap(nil)), 10, func(ctx context.Context, index int) error {
+		time.Sleep(time.Duration(index+1) * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	s := stats.Stats()
+	if s.Count != 10 {
+		t.Fatalf("Expecting durations of 10 workers, got %d", s.Count)
+	}
+	if s.Min < time.Millisecond || s.Max < 10*time.Millisecond || s.Min > s.P50 || s.P50 > s.P99 || s.P99 > s.Max {
+		t.Fatalf("Unexpected duration statistics: %+v", s)
+	}
+	if s.Mean < 5*time.Millisecond {
+		t.Fatalf("Expecting mean duration of at least 5ms, got %s", s.Mean)
+	}
+}
+
+func TestCollectStatsBounded(t *testing.T) {
+
+	stats := CollectStats()
+	for i := 1; i <= 10000; i++ {
+		stats.record(time.Duration(i) * time.Millisecond)
+	}
+
+	s := stats.Stats()
+	if len(stats.durations) != statsSample {
+		t.Fatalf("Expecting a sample of %d durations, got %d", statsSample, len(stats.durations))
+	}
+	if s.Count != 10000 || s.Min != time.Millisecond || s.Max != 10000*time.Millisecond {
+		t.Fatalf("Expecting exact count, minimum and maximum: %+v", s)
+	}
+	if s.Mean != 5000500*time.Microsecond {
+		t.Fatalf("Expecting exact mean duration of 5000.5ms, got %s", s.Mean)
+	}
+	if s.Min > s.P50 || s.P50 > s.P95 || s.P95 > s.P99 || s.P99 > s.Max {
+		t.Fatalf("Unexpected duration statistics: %+v", s)
+	}
+}
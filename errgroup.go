@@ -0,0 +1,67 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+)
+
+type errgroupExecuter struct {
+	g interface{ Go(func() error) }
+}
+
+// FromErrgroup returns an executer that executes functions with the
+// Go method of the group, g, such as an errgroup.Group from the package
+// golang.org/x/sync/errgroup, so that the limit configured for the
+// group also limits the workers of work groups that use the executer.
+// The functions always return nil to the group, the errors of the
+// workers are handled by the manager of the work group, but note that
+// the Wait method of the group, g, must still be called by its owner.
+func FromErrgroup(g interface{ Go(func() error) }) Executer {
+	return &errgroupExecuter{g: g}
+}
+
+func (e *errgroupExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	e.g.Go(func() error {
+		f(ctx)
+		return nil
+	})
+}
+
+// ErrGroup provides the API of errgroup.Group from the package
+// golang.org/x/sync/errgroup, backed by a work group, so that
+// call sites can be migrated to workgroup incrementally.
+type ErrGroup struct {
+	grp   *group
+	mutex sync.Mutex
+	next  int
+}
+
+// NewErrGroup returns a new ErrGroup and the derived context
+// with which the functions of the group are executed, similar to
+// errgroup.WithContext. The executer, e, and the manager, m, are
+// used as for Work(), by default the context is cancelled the
+// first time a function returns an error.
+func NewErrGroup(ctx context.Context, e Executer, m Manager) (*ErrGroup, context.Context) {
+	grp := newGroup(ctx, e, m)
+	return &ErrGroup{grp: grp}, grp.ctx
+}
+
+// Go executes the function, f, with the executer of the group.
+func (g *ErrGroup) Go(f func() error) {
+	g.mutex.Lock()
+	index := g.next
+	g.next++
+	g.mutex.Unlock()
+
+	g.grp.execute(index, func(context.Context) error {
+		return f()
+	})
+}
+
+// Wait waits for all the functions of the group to complete,
+// cancels the context of the group and returns the error
+// provided by the manager.
+func (g *ErrGroup) Wait() error {
+	defer g.grp.close()
+	return g.grp.wait()
+}
@@ -0,0 +1,68 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// testErrgroup is a minimal stand-in for errgroup.Group.
+type testErrgroup struct {
+	wg    sync.WaitGroup
+	calls int
+	mutex sync.Mutex
+}
+
+func (g *testErrgroup) Go(f func() error) {
+	g.mutex.Lock()
+	g.calls++
+	g.mutex.Unlock()
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		f()
+	}()
+}
+
+func TestFromErrgroup(t *testing.T) {
+
+	eg := &testErrgroup{}
+	failed := errors.New("failed")
+
+	err := WorkFor(context.Background(), FromErrgroup(eg), nil, 5, func(ctx context.Context, index int) error {
+		if index == 3 {
+			return failed
+		}
+		return nil
+	})
+	eg.wg.Wait()
+
+	if err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+	if eg.calls != 5 {
+		t.Fatalf("Expecting 5 functions executed by the errgroup, got %d", eg.calls)
+	}
+}
+
+func TestErrGroup(t *testing.T) {
+
+	failed := errors.New("failed")
+	g, ctx := NewErrGroup(context.Background(), nil, nil)
+
+	g.Go(func() error {
+		return failed
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := g.Wait(); err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+	if ctx.Err() == nil {
+		t.Fatalf("Expecting context cancelled after wait")
+	}
+}
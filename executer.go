@@ -3,6 +3,8 @@ package workgroup
 import (
 	"context"
 	"runtime"
+	"sync"
+	"time"
 )
 
 // DefaultLimit is used for limited and pool executers
@@ -11,8 +13,25 @@ import (
 var DefaultLimit = runtime.NumCPU()
 
 // DefaultExecuter is a function that provides the default Executer.
+// It may be set to NewCPUBound, for example, so that work groups
+// started without an executer do not oversubscribe the CPUs.
 var DefaultExecuter = NewUnlimited
 
+// SetDefaultExecuter configures the executer, e, as the executer of work
+// groups started without an executer, see DefaultExecuter. The executer
+// is shared by all of these work groups, so that they share its limit.
+// It should be called when the application is initialized, before work
+// groups are started. If e is nil, then NewUnlimited is restored.
+func SetDefaultExecuter(e Executer) {
+	if e == nil {
+		DefaultExecuter = NewUnlimited
+		return
+	}
+	DefaultExecuter = func() Executer {
+		return e
+	}
+}
+
 // Executer arranges for function, f, to executed.
 type Executer interface {
 	Execute(ctx context.Context, f func(ctx context.Context))
@@ -30,13 +49,39 @@ func (u *unlimited) Execute(ctx context.Context, f func(context.Context)) {
 	go f(ctx)
 }
 
+type serial struct{}
+
+// NewSerial returns an executer that executes functions
+// on the calling goroutine, in the order that they are
+// submitted. It makes tests of code built on work groups
+// deterministic, and is useful when debugging to rule out
+// concurrency as the cause of a problem.
+func NewSerial() Executer {
+	return &serial{}
+}
+
+func (s *serial) Execute(ctx context.Context, f func(context.Context)) {
+	f(ctx)
+}
+
 type limited struct {
-	ch chan struct{}
+	ch       chan struct{}
+	heavy    chan struct{}
+	counts   counters
+	bounded  bool
+	capacity int64
+	mutex    sync.Mutex
+	queue    []queuedFunc
 }
 
 // NewLimited returns an executer that will execute functions
 // on at most, n, goroutines simultaneously. If n <= 0 then
-// the value provided by DefaultLimit will be used.
+// the value provided by DefaultLimit will be used. The
+// executer implements StatsProvider. The executer is safe to
+// share between concurrent work groups, which then share the
+// limit, including work groups nested within them, see Work().
+// A worker can occupy more than one of the goroutines of the
+// limit by declaring its cost with the Weighted worker wrapper.
 func NewLimited(n int) Executer {
 	if n <= 0 {
 		n = DefaultLimit
@@ -45,8 +90,27 @@ func NewLimited(n int) Executer {
 		n = runtime.NumCPU()
 	}
 	return &limited{
-		ch: make(chan struct{}, n),
+		ch:    make(chan struct{}, n),
+		heavy: make(chan struct{}, 1),
+	}
+}
+
+// NewCPUBound returns a limited executer, see NewLimited, that will
+// execute functions on at most runtime.GOMAXPROCS goroutines, which
+// suits workers that are bound by computation.
+func NewCPUBound() Executer {
+	return NewLimited(runtime.GOMAXPROCS(0))
+}
+
+// NewIOBound returns a limited executer, see NewLimited, that will
+// execute functions on at most multiplier times runtime.GOMAXPROCS
+// goroutines, which suits workers that spend most of their time
+// waiting for I/O. If multiplier <= 0 then a multiplier of one is used.
+func NewIOBound(multiplier int) Executer {
+	if multiplier <= 0 {
+		multiplier = 1
 	}
+	return NewLimited(multiplier * runtime.GOMAXPROCS(0))
 }
 
 func (l *limited) add() {
@@ -58,51 +122,372 @@ func (l *limited) done() {
 }
 
 func (l *limited) Execute(ctx context.Context, f func(context.Context)) {
+	if l.bounded {
+		l.enqueue(ctx, f)
+		return
+	}
+	l.counts.enqueue()
 	l.add()
+	l.counts.dequeue()
+	l.start(ctx, f)
+}
+
+// TryExecute executes the function, f, on a new goroutine
+// if a slot is available, and reports whether it was.
+func (l *limited) TryExecute(ctx context.Context, f func(context.Context)) bool {
+	select {
+	case l.ch <- struct{}{}:
+		l.start(ctx, f)
+		return true
+	default:
+		return false
+	}
+}
+
+// start calls the function, f, on a new goroutine
+// that releases the slot held when it completes.
+func (l *limited) start(ctx context.Context, f func(context.Context)) {
 	go func() {
-		defer l.done()
+		defer l.release()
+		l.counts.start()
+		defer l.counts.finish()
 		f(ctx)
 	}()
 }
 
-type pool struct {
-	ch chan func()
+// release releases a slot, which starts a queued function
+// if the executer is bounded, see NewBoundedLimited.
+func (l *limited) release() {
+	l.done()
+	if l.bounded {
+		l.dispatch()
+	}
 }
 
-// NewPool initializes a new pool executer that will execute
-// functions on fixed number of goroutines. If n <= 0 then
-// the values in DefaultLimit is used. Note that the provided
-// context must be cancelled to ensure that the pool releases
-// all resources.
-func NewPool(ctx context.Context, n int) Executer {
-	if n <= 0 {
-		n = DefaultLimit
+// Stats returns the runtime statistics of the executer, the
+// queued functions are those waiting for a goroutine.
+func (l *limited) Stats() Stats {
+	return l.counts.stats()
+}
+
+// lend releases the slot held by the worker that is starting a
+// nested group with this executer, and returns a function that
+// reacquires the slot when the nested group has completed.
+func (l *limited) lend() func() {
+	l.release()
+	return l.add
+}
+
+// weigh exchanges the slot held by a worker for n slots, and returns
+// a function that releases the slots other than the one held by the
+// worker. The slot held by the worker is released before the n slots
+// are acquired, and the workers acquiring more than one slot do so one
+// at a time, so that workers waiting for slots cannot deadlock. The
+// number of slots is clamped to the limit of the executer. If the
+// context, ctx, is done first, then the slot held by the worker is
+// reacquired and the error of the context is returned.
+func (l *limited) weigh(ctx context.Context, n int) (func(), error) {
+	if n > cap(l.ch) {
+		n = cap(l.ch)
 	}
-	if n <= 0 {
-		n = runtime.NumCPU()
+	if n <= 1 {
+		return func() {}, nil
+	}
+	l.release()
+	acquired, err := l.acquire(ctx, n)
+	if err != nil {
+		// the worker keeps one slot, which is reacquired if need be
+		for i := 1; i < acquired; i++ {
+			l.release()
+		}
+		if acquired == 0 {
+			l.add()
+		}
+		return nil, err
 	}
+	return func() {
+		for i := 1; i < n; i++ {
+			l.release()
+		}
+	}, nil
+}
+
+// acquire acquires n slots for one worker at a time, or returns the
+// number of slots acquired and the error of the context, ctx, if it
+// is done first.
+func (l *limited) acquire(ctx context.Context, n int) (int, error) {
+	select {
+	case l.heavy <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	defer func() { <-l.heavy }()
+	for i := 0; i < n; i++ {
+		select {
+		case l.ch <- struct{}{}:
+		case <-ctx.Done():
+			return i, ctx.Err()
+		}
+	}
+	return n, nil
+}
 
-	p := &pool{
-		ch: make(chan func()),
+// weigher is implemented by executers that can execute a worker
+// with more than one slot of their limit, see Weighted.
+type weigher interface {
+	weigh(ctx context.Context, n int) (release func(), err error)
+}
+
+// lender is implemented by executers that can lend the capacity
+// held by a worker to a nested group that uses the same executer.
+type lender interface {
+	lend() (reclaim func())
+}
+
+// lendSlot lends the slot of the executer, e, that is held by the worker
+// with the context, ctx, to a nested group that uses the same executer,
+// and returns a function that reclaims the slot, or nil if the worker
+// holds no slot of the executer, for example, as it has returned or its
+// slot is lent to another nested group.
+func lendSlot(ctx context.Context, e Executer) func() {
+	l, ok := e.(lender)
+	j, _ := ctx.Value(jobKey{}).(*job)
+	if !ok || j == nil || j.g.e != e {
+		return nil
+	}
+	restore := j.borrow()
+	if restore == nil {
+		return nil
+	}
+	reclaim := l.lend()
+	return func() {
+		reclaim()
+		restore()
 	}
+}
 
-	if ctx != nil {
-		go func() {
-			<-ctx.Done()
-			close(p.ch)
-		}()
+// weighSlot exchanges the slot of the executer of its work group that is
+// held by the worker with the context, ctx, for n slots, see weigher, and
+// returns a function that restores the slot of the worker. The function is
+// nil if the worker holds no slot of an executer that weighs workers, for
+// example, as it has returned, or its slot is lent to a nested group or
+// weighed by another call.
+func weighSlot(ctx context.Context, n int) (func(), error) {
+	j, _ := ctx.Value(jobKey{}).(*job)
+	if j == nil {
+		return nil, nil
+	}
+	w, ok := j.g.e.(weigher)
+	if !ok {
+		return nil, nil
+	}
+	restore := j.borrow()
+	if restore == nil {
+		return nil, nil
 	}
+	release, err := w.weigh(ctx, n)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	return func() {
+		release()
+		restore()
+	}, nil
+}
 
-	for i := 0; i < n; i++ {
-		go func() {
-			for f := range p.ch {
-				f()
-			}
-		}()
+// hold records that the worker of the job holds a slot of the executer
+// of its group, and returns a function that waits for a borrowed slot to
+// be restored, and then records that the worker holds no slot.
+func (j *job) hold() func() {
+	j.mutex.Lock()
+	j.slot = true
+	j.mutex.Unlock()
+	return func() {
+		j.mutex.Lock()
+		for j.lent != nil {
+			// the slot is released by the executer once the worker returns
+			lent := j.lent
+			j.mutex.Unlock()
+			<-lent
+			j.mutex.Lock()
+		}
+		j.slot = false
+		j.mutex.Unlock()
 	}
-	return p
 }
 
-func (p *pool) Execute(ctx context.Context, f func(context.Context)) {
-	p.ch <- func() { f(ctx) }
+// borrow borrows the slot held by the worker of the job, and returns
+// a function that restores it, or nil if the worker holds no slot.
+func (j *job) borrow() func() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if !j.slot || j.lent != nil {
+		return nil
+	}
+	lent := make(chan struct{})
+	j.lent = lent
+	return func() {
+		j.mutex.Lock()
+		j.lent = nil
+		j.mutex.Unlock()
+		close(lent)
+	}
+}
+
+type executerKey struct{}
+
+type skipKey struct{}
+
+// SkipIfCancelled returns a copy of the context, ctx, that configures
+// work groups started with it, and all work groups nested within them,
+// to skip the workers whose context is cancelled when they are submitted
+// to the executer, so that no goroutine is scheduled for them, or when
+// the executer starts them. The manager handles a skipped worker as if
+// it completed with the error of its context. By default every worker
+// is invoked.
+func SkipIfCancelled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+type stopKey struct{}
+
+// StopIfCancelled returns a copy of the context, ctx, that configures
+// work groups started with it, and all work groups nested within them,
+// to stop generating workers once the context of the group is cancelled
+// or the group is shut down, see Scope.Shutdown. The remaining workers
+// are neither invoked nor passed to the manager, so that the cost of a
+// work group with millions of workers, such as WorkFor with a limited
+// executer, does not grow with the number of workers once it has been
+// cancelled. Workers that are provided by a channel are not received
+// once the group is cancelled regardless, see WorkChan.
+func StopIfCancelled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stopKey{}, true)
+}
+
+type inheritKey struct{}
+
+// InheritExecuter returns a copy of the context, ctx, that configures
+// work groups started with it, and all work groups nested within them,
+// to inherit the executer of the enclosing work group when no executer
+// is provided. This closes the gap where a nested group with a nil
+// executer would otherwise use DefaultExecuter inside a bounded parent.
+func InheritExecuter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, inheritKey{}, true)
+}
+
+type inherit struct{}
+
+// Inherit returns an executer that is resolved to the executer of
+// the enclosing work group when it is used for a nested work group.
+// If the work group is not nested, then DefaultExecuter is called
+// to obtain the default executer.
+func Inherit() Executer {
+	return inherit{}
+}
+
+func (inherit) Execute(ctx context.Context, f func(context.Context)) {
+	inherited(ctx).Execute(ctx, f)
+}
+
+// inherited returns the executer of the work group
+// enclosing the context, ctx, or the default executer.
+func inherited(ctx context.Context) Executer {
+	if e, ok := ctx.Value(executerKey{}).(Executer); ok {
+		return e
+	}
+	return DefaultExecuter()
+}
+
+// Limiter waits until an event is permitted to happen. It is
+// implemented by *rate.Limiter of the golang.org/x/time/rate module.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+type rateLimited struct {
+	l Limiter
+	e Executer
+}
+
+// NewRateLimited returns an executer that will start functions
+// at a rate of at most rps per second with bursts of at most
+// burst functions. The number of functions executing in parallel
+// is not limited. If rps <= 0 then the rate is not limited and
+// if burst <= 0 then a burst of one function is used.
+func NewRateLimited(rps float64, burst int) Executer {
+	return RateLimit(newTokenBucket(rps, burst), nil)
+}
+
+// RateLimit returns an executer that waits for the limiter, l,
+// before passing each function to the executer, e, for execution.
+// If executer, e, is not provided then NewUnlimited is used. If
+// waiting fails, for example the context is cancelled, then the
+// function is executed immediately with its context.
+func RateLimit(l Limiter, e Executer) Executer {
+	if e == nil {
+		e = NewUnlimited()
+	}
+	return &rateLimited{l: l, e: e}
+}
+
+func (r *rateLimited) Execute(ctx context.Context, f func(context.Context)) {
+	r.l.Wait(ctx)
+	r.e.Execute(ctx, f)
+}
+
+// tokenBucket is a simple token bucket rate limiter.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rps float64, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Wait reserves a token from the bucket and waits until
+// the token is available or the context, ctx, is done.
+// The time is measured by the clock of the context.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if b.rate <= 0 {
+		return nil
+	}
+	clock := ClockFrom(ctx)
+
+	b.mutex.Lock()
+	now := clock.Now()
+	if b.last.IsZero() {
+		b.last = now
+	}
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, clock, wait); err != nil {
+		b.mutex.Lock()
+		b.tokens++
+		b.mutex.Unlock()
+		return err
+	}
+	return nil
 }
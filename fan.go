@@ -0,0 +1,63 @@
+package workgroup
+
+import "context"
+
+// FanOut splits the items provided by the channel, in, across k channels,
+// for example, to be consumed by k work groups. Each item is sent on one
+// of the channels, whichever is ready to receive it first. The channels
+// are closed when the channel, in, is closed and its items have been sent,
+// or when the context, ctx, is done, in which case pending items are
+// discarded. If k <= 0 then one channel is used.
+func FanOut[T any](ctx context.Context, in <-chan T, k int) []<-chan T {
+	if k <= 0 {
+		k = 1
+	}
+	outs := make([]chan T, k)
+	result := make([]<-chan T, k)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+
+	go WorkFor(ctx, nil, CancelNeverFirstError(), k, func(ctx context.Context, index int) error {
+		defer close(outs[index])
+		return forward(ctx, in, outs[index])
+	})
+	return result
+}
+
+// FanIn merges the items provided by the channels, ins, into a single
+// channel. The channel is closed when all of the channels, ins, have been
+// closed and their items have been sent, or when the context, ctx, is done,
+// in which case pending items are discarded and the channels, ins, are no
+// longer received from, so their producers should also observe ctx.
+func FanIn[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		WorkFor(ctx, nil, CancelNeverFirstError(), len(ins), func(ctx context.Context, index int) error {
+			return forward(ctx, ins[index], out)
+		})
+	}()
+	return out
+}
+
+// forward sends the items provided by the channel, in, on the channel,
+// out, until in is closed or the context, ctx, is done.
+func forward[T any](ctx context.Context, in <-chan T, out chan<- T) error {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return nil
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package workgroup
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFanOutFanIn(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 1; i <= 100; i++ {
+			in <- i
+		}
+	}()
+
+	outs := FanOut(ctx, in, 4)
+	results := make([]<-chan int, len(outs))
+	for i, c := range outs {
+		squares := make(chan int)
+		results[i] = squares
+		go func(c <-chan int) {
+			defer close(squares)
+			for v := range c {
+				squares <- v * v
+			}
+		}(c)
+	}
+
+	sum := 0
+	for v := range FanIn(ctx, results...) {
+		sum += v
+	}
+	if sum != 338350 {
+		t.Fatalf("Expecting sum of squares 338350, got %d", sum)
+	}
+}
+
+func TestFanInCancel(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the inputs are never closed
+	out := FanIn(ctx, make(chan int), make(chan int))
+	cancel()
+	for range out {
+	}
+}
@@ -0,0 +1,125 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInvalidCount is returned by WorkFirstN if the count is not positive.
+var ErrInvalidCount = errors.New("workgroup: invalid count")
+
+// ErrTooFewSucceeded is matched by the error of WorkFirstN, using
+// errors.Is, if fewer than the requested number of workers succeeded.
+var ErrTooFewSucceeded = errors.New("workgroup: too few workers succeeded")
+
+// WorkFirstN arranges for the workers to be executed and returns the
+// results of the first k workers to complete without error, in the order
+// that they completed. The work group context is cancelled once k workers
+// have succeeded, or once so many workers have failed that fewer than k
+// can succeed. If fewer than k workers succeed, then the error reports how
+// many succeeded and matches both ErrTooFewSucceeded and the first error,
+// or the error of the context if no worker failed, using errors.Is. If k
+// is greater than the number of workers, then no worker is executed. If
+// k is not positive, then no worker is executed and the error is
+// ErrInvalidCount. This generalizes CancelOnFirstSuccess, which discards
+// the result of the successful worker. See documention for Work() for
+// details.
+func WorkFirstN[T any](ctx context.Context, e Executer, k int, workers ...func(context.Context) (T, error)) ([]Result[T], error) {
+	if k <= 0 {
+		return nil, ErrInvalidCount
+	}
+	if k > len(workers) {
+		return nil, &firstNError{k: k}
+	}
+	m := &firstN{k: k, n: len(workers)}
+
+	var mutex sync.Mutex
+	results := make([]Result[T], 0, k)
+	err := WorkFor(ctx, e, m, len(workers), func(ctx context.Context, index int) error {
+		v, err := workers[index](ctx)
+		if err == nil {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if len(results) < k {
+				results = append(results, Result[T]{Index: index, Value: v})
+			}
+		}
+		return err
+	})
+	if err != nil {
+		if e, ok := err.(*firstNError); ok && e.err == nil {
+			e.err = ctx.Err()
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// firstNError is the error of WorkFirstN when
+// fewer than k of the workers succeeded.
+type firstNError struct {
+	n   int
+	k   int
+	err error
+}
+
+func (e *firstNError) Error() string {
+	msg := fmt.Sprintf("workgroup: only %d of %d workers succeeded", e.n, e.k)
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+func (e *firstNError) Is(target error) bool {
+	return target == ErrTooFewSucceeded
+}
+
+func (e *firstNError) Unwrap() error {
+	return e.err
+}
+
+// firstN is a manager that cancels the work group context when
+// k of the n workers have completed without error, or when fewer
+// than k of the workers can complete without error.
+type firstN struct {
+	mutex    sync.Mutex
+	k        int
+	n        int
+	nsuccess int
+	nerror   int
+	err      error
+}
+
+func (m *firstN) Error() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.nsuccess >= m.k {
+		return nil
+	}
+	return &firstNError{n: m.nsuccess, k: m.k, err: m.err}
+}
+
+func (m *firstN) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if *err != nil {
+		m.nerror++
+		if m.err == nil && m.nsuccess < m.k {
+			m.err = *err
+		}
+		if m.n-m.nerror < m.k {
+			c.Cancel()
+		}
+	} else {
+		m.nsuccess++
+		if m.nsuccess == m.k {
+			c.Cancel()
+		}
+	}
+
+	return m.nsuccess + m.nerror
+}
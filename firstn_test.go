@@ -0,0 +1,63 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWorkFirstN(t *testing.T) {
+
+	failed := errors.New("failed")
+	workers := make([]func(context.Context) (int, error), 5)
+	for i := range workers {
+		n := i
+		workers[i] = func(ctx context.Context) (int, error) {
+			if n%2 == 0 {
+				return 0, failed
+			}
+			return n, nil
+		}
+	}
+
+	results, err := WorkFirstN(context.Background(), NewSerial(), 2, workers...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].Value != 1 || results[1].Index != 3 {
+		t.Fatalf("Expecting results of workers 1 and 3, got %+v", results)
+	}
+
+	results, err = WorkFirstN(context.Background(), NewSerial(), 3, workers...)
+	if !errors.Is(err, ErrTooFewSucceeded) || !errors.Is(err, failed) || results != nil {
+		t.Fatalf("Expecting error %v, got %v: %+v", failed, err, results)
+	}
+	if err.Error() != "workgroup: only 2 of 3 workers succeeded: failed" {
+		t.Fatalf("Unexpected error message: %v", err)
+	}
+}
+
+func TestWorkFirstNInvalid(t *testing.T) {
+
+	var invoked int
+	workers := make([]func(context.Context) (int, error), 2)
+	for i := range workers {
+		workers[i] = func(ctx context.Context) (int, error) {
+			invoked++
+			return 0, nil
+		}
+	}
+
+	_, err := WorkFirstN(context.Background(), NewSerial(), 0, workers...)
+	if err != ErrInvalidCount {
+		t.Fatalf("Expecting error %v, got %v", ErrInvalidCount, err)
+	}
+
+	_, err = WorkFirstN(context.Background(), NewSerial(), 3, workers...)
+	if !errors.Is(err, ErrTooFewSucceeded) || err.Error() != "workgroup: only 0 of 3 workers succeeded" {
+		t.Fatalf("Expecting error %v, got %v", ErrTooFewSucceeded, err)
+	}
+	if invoked != 0 {
+		t.Fatalf("Expecting no worker invoked, got %d", invoked)
+	}
+}
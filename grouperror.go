@@ -0,0 +1,124 @@
+package workgroup
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FailedGroupError is the error of a named work group, see WithGroupName,
+// that annotates the error of the group with the name of the group and the
+// number of workers that failed, so that the errors of nested groups can be
+// attributed to them.
+type FailedGroupError struct {
+	// Group is the name of the work group.
+	Group string
+
+	// Failed is the number of workers that completed with an error.
+	Failed int
+
+	// Total is the number of workers of the work group.
+	Total int
+
+	// Err is the error of the work group provided by the manager.
+	Err error
+}
+
+func (e *FailedGroupError) Error() string {
+	msg := e.Err.Error()
+	if we, ok := e.Err.(*WorkerError); ok && we.Group == e.Group {
+		// the name of the group is not repeated
+		c := *we
+		c.Group = ""
+		msg = c.Error()
+	}
+	return "workgroup " + strconv.Quote(e.Group) + ": " + strconv.Itoa(e.Failed) + "/" + strconv.Itoa(e.Total) + " workers failed: " + msg
+}
+
+func (e *FailedGroupError) Unwrap() error {
+	return e.Err
+}
+
+// GroupError is an error that contains the errors of the
+// workers of a work group by the index of the worker, so
+// that failures can be mapped back to the inputs of the
+// workers, see AccumulateManager.GroupError.
+type GroupError struct {
+	errs    map[int]error
+	indices []int
+}
+
+func newGroupError(errs map[int]error) *GroupError {
+	e := &GroupError{errs: make(map[int]error, len(errs))}
+	for idx, err := range errs {
+		e.errs[idx] = err
+		e.indices = append(e.indices, idx)
+	}
+	sort.Ints(e.indices)
+	return e
+}
+
+func (e *GroupError) Error() string {
+	if len(e.indices) == 1 {
+		return e.errs[e.indices[0]].Error()
+	}
+	msgs := make([]string, len(e.indices))
+	for i, idx := range e.indices {
+		msgs[i] = e.errs[idx].Error()
+	}
+	return strconv.Itoa(len(msgs)) + " errors: " + strings.Join(msgs, "; ")
+}
+
+// Len returns the number of workers that completed with an error.
+func (e *GroupError) Len() int {
+	return len(e.indices)
+}
+
+// ByIndex returns the error of the worker with index, i. The result is
+// nil if the worker completed successfully or its error was not kept.
+func (e *GroupError) ByIndex(i int) error {
+	return e.errs[i]
+}
+
+// Indices returns the indices of the workers that
+// completed with an error in ascending order.
+func (e *GroupError) Indices() []int {
+	indices := make([]int, len(e.indices))
+	copy(indices, e.indices)
+	return indices
+}
+
+// Unwrap returns the errors of the workers in the order of their
+// indices, which errors.Is and errors.As match since Go 1.20.
+func (e *GroupError) Unwrap() []error {
+	errs := make([]error, len(e.indices))
+	for i, idx := range e.indices {
+		errs[i] = e.errs[idx]
+	}
+	return errs
+}
+
+// Is reports whether any of the errors of the workers matches the
+// error, target, so that errors.Is matches them before Go 1.20.
+func (e *GroupError) Is(target error) bool {
+	for _, idx := range e.indices {
+		if errors.Is(e.errs[idx], target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors of the workers, in the order of
+// their indices, that matches the target, and if so, sets the target
+// to that error and returns true, so that errors.As matches them
+// before Go 1.20.
+func (e *GroupError) As(target interface{}) bool {
+	for _, idx := range e.indices {
+		if errors.As(e.errs[idx], target) {
+			return true
+		}
+	}
+	return false
+}
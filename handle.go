@@ -0,0 +1,174 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed is the error returned when a worker is added
+// to a work group that has been closed, see Handle.
+var ErrClosed = errors.New("workgroup: group closed")
+
+type handleKey struct{}
+
+// Handle is a work group to which workers can be added while it runs,
+// see NewHandle. Unlike WorkChan, no producer goroutine is required and
+// each addition reports whether the worker was accepted.
+type Handle struct {
+	grp *group
+
+	mutex  sync.Mutex
+	next   int
+	closed bool
+	close  chan struct{}
+
+	watch    sync.Once
+	finished chan struct{}
+
+	once sync.Once
+	err  error
+}
+
+// NewHandle starts a new work group to which workers are added by Add.
+// The executer, e, and the manager, m, are used as for Work(). The work
+// group completes when it has been closed, see Close, and the workers
+// that were added have completed.
+func NewHandle(ctx context.Context, e Executer, m Manager) *Handle {
+	h := &Handle{
+		grp:      newGroup(context.WithValue(ctx, handleKey{}, true), e, m),
+		close:    make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	h.grp.handle = h
+	return h
+}
+
+// Add submits the worker, w, to the work group and returns its index.
+// The worker is rejected, and not executed, with ErrClosed if the group
+// has been closed, or with the error of the context of the group if it
+// has been cancelled, or with context.Canceled if it has been shut down.
+func (h *Handle) Add(w Worker) (int, error) {
+	h.mutex.Lock()
+	if h.closed {
+		h.mutex.Unlock()
+		return 0, ErrClosed
+	}
+	if err := h.grp.ctx.Err(); err != nil {
+		h.mutex.Unlock()
+		return 0, err
+	}
+	if h.grp.stopped() {
+		h.mutex.Unlock()
+		return 0, context.Canceled
+	}
+	index := h.next
+	h.next++
+	// the worker is counted before the lock is released, so that the
+	// group is not drained while the executer is busy, see Done
+	h.grp.wg.Add(1)
+	h.mutex.Unlock()
+
+	defer h.grp.wg.Done()
+	h.grp.execute(index, w)
+	return index, nil
+}
+
+// Close signals that no more workers will be added to the work group.
+func (h *Handle) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if !h.closed {
+		h.closed = true
+		close(h.close)
+	}
+}
+
+// Wait waits for the work group to be closed, or shut down, and for
+// the workers that were added to complete, cancels the context of the group
+// and returns the error provided by the manager.
+func (h *Handle) Wait() error {
+	return h.WaitContext(context.Background())
+}
+
+// WaitContext is the same as Wait, except that it stops waiting when the
+// context, ctx, is done and returns its error, without cancelling the work
+// group, so that a caller can respond while the work continues. The group
+// can be waited for again later.
+func (h *Handle) WaitContext(ctx context.Context) error {
+	select {
+	case <-h.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return h.result()
+}
+
+// result returns the error of the completed group, the manager is
+// consulted on the calling goroutine, since Repanic may panic.
+func (h *Handle) result() error {
+	h.once.Do(func() {
+		defer h.grp.close()
+		h.err = h.grp.wait()
+	})
+	return h.err
+}
+
+// Done returns a channel that is closed when the work group has been
+// closed, or shut down, and the workers that were added have completed.
+func (h *Handle) Done() <-chan struct{} {
+	h.watch.Do(func() {
+		go func() {
+			select {
+			case <-h.close:
+			case <-h.grp.stop:
+			}
+			h.grp.wg.Wait()
+			close(h.finished)
+		}()
+	})
+	return h.finished
+}
+
+// Err returns the error provided by the manager once the work group
+// has completed, see Done, and nil before the group has completed.
+func (h *Handle) Err() error {
+	select {
+	case <-h.Done():
+		return h.result()
+	default:
+		return nil
+	}
+}
+
+// Total returns the number of workers that were added to the work group.
+func (h *Handle) Total() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.next
+}
+
+// Completed returns the number of workers of the work group that have
+// completed, including the workers that were not invoked, for example,
+// after the group was shut down.
+func (h *Handle) Completed() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.next - int(atomic.LoadInt64(&h.grp.running))
+}
+
+// WaitTimeout is the same as WaitContext with a context
+// that is done after the duration, d, has elapsed.
+func (h *Handle) WaitTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return h.WaitContext(ctx)
+}
+
+// Scope returns the scope of the work group, to inspect or control it.
+func (h *Handle) Scope() *Scope {
+	return &Scope{g: h.grp}
+}
@@ -0,0 +1,216 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandle(t *testing.T) {
+
+	h := NewHandle(context.Background(), NewLimited(2), nil)
+
+	var sum int64
+	for i := 1; i <= 10; i++ {
+		n := int64(i)
+		index, err := h.Add(func(ctx context.Context) error {
+			atomic.AddInt64(&sum, n)
+			return nil
+		})
+		if err != nil || index != i-1 {
+			t.Fatalf("Expecting worker %d added, got %d: %v", i-1, index, err)
+		}
+	}
+	h.Close()
+
+	if _, err := h.Add(func(ctx context.Context) error { return nil }); err != ErrClosed {
+		t.Fatalf("Expecting worker rejected by closed group, got %v", err)
+	}
+	if err := h.Wait(); err != nil || sum != 55 {
+		t.Fatalf("Expecting sum of 55, got %d: %v", sum, err)
+	}
+
+	failed := errors.New("failed")
+	h = NewHandle(context.Background(), NewSerial(), CancelOnFirstError())
+	h.Add(func(ctx context.Context) error { return failed })
+	if _, err := h.Add(func(ctx context.Context) error { return nil }); err != context.Canceled {
+		t.Fatalf("Expecting worker rejected by cancelled group, got %v", err)
+	}
+	h.Close()
+	if err := h.Wait(); err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+}
+
+func TestHandleWaitTimeout(t *testing.T) {
+
+	h := NewHandle(context.Background(), nil, nil)
+	release := make(chan struct{})
+	h.Add(func(ctx context.Context) error {
+		<-release
+		return ctx.Err()
+	})
+	if err := h.WaitTimeout(time.Millisecond); err != context.DeadlineExceeded {
+		t.Fatalf("Expecting wait for unclosed group to time out, got %v", err)
+	}
+	h.Close()
+	if err := h.WaitTimeout(time.Millisecond); err != context.DeadlineExceeded {
+		t.Fatalf("Expecting wait for running group to time out, got %v", err)
+	}
+
+	// the group is not cancelled by the timeout
+	close(release)
+	if err := h.Wait(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestHandleDone(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	h := NewHandle(context.Background(), nil, CancelNeverFirstError())
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		index := i
+		h.Add(func(ctx context.Context) error {
+			if index == 0 {
+				return failed
+			}
+			<-release
+			return nil
+		})
+	}
+	for h.Completed() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if n, total := h.Completed(), h.Total(); n != 1 || total != 3 {
+		t.Fatalf("Expecting 1 of 3 workers completed, got %d of %d", n, total)
+	}
+	h.Close()
+
+	select {
+	case <-h.Done():
+		t.Fatalf("Expecting group not done while workers are running")
+	default:
+	}
+	if err := h.Err(); err != nil {
+		t.Fatalf("Expecting no error before the group is done: %v", err)
+	}
+
+	close(release)
+	select {
+	case <-h.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("Expecting group done after workers completed")
+	}
+	if err := h.Err(); err != failed || h.Completed() != 3 {
+		t.Fatalf("Expecting error %v with 3 workers completed, got %v", failed, err)
+	}
+}
+
+func TestHandleOnCancel(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	causes := make(chan error, 2)
+	h := NewHandle(context.Background(), nil, CancelOnFirstError())
+	h.OnCancel(func(cause error) { causes <- cause })
+	h.Add(func(ctx context.Context) error { return failed })
+	if err := <-causes; err != failed {
+		t.Fatalf("Expecting cause %v, got %v", failed, err)
+	}
+	h.Close()
+	h.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h = NewHandle(ctx, nil, nil)
+	h.OnCancel(func(cause error) { causes <- cause })
+	cancel()
+	if err := <-causes; err != context.Canceled {
+		t.Fatalf("Expecting cause %v, got %v", context.Canceled, err)
+	}
+	h.Close()
+	h.Wait()
+
+	// a group that completes is not cancelled
+	err := Work(context.Background(), nil, nil, func(ctx context.Context) error {
+		CurrentGroup(ctx).OnCancel(func(cause error) { causes <- cause })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	select {
+	case err := <-causes:
+		t.Fatalf("Expecting no cancellation after completion, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestHandleDump(t *testing.T) {
+
+	h := NewHandle(context.Background(), nil, nil)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	h.Add(func(ctx context.Context) error { return nil })
+	h.Add(func(ctx context.Context) error {
+		close(started)
+		<-block
+		return nil
+	})
+	<-started
+	for h.Completed() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	infos := h.Scope().Dump()
+	if len(infos) != 1 || infos[0].Index != 1 {
+		t.Fatalf("Expecting worker 1 running, got %+v", infos)
+	}
+	close(block)
+	h.Close()
+	h.Wait()
+}
+
+func TestHandleShutdown(t *testing.T) {
+
+	h := NewHandle(context.Background(), NewLimited(1), CancelNeverFirstError())
+	release := make(chan struct{})
+	h.Add(func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	// the lock of the handle is not held while the executer is saturated
+	added := make(chan error)
+	go func() {
+		_, err := h.Add(func(ctx context.Context) error { return nil })
+		added <- err
+	}()
+	for h.Total() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if err := <-added; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for h.Completed() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the handle is shut down before it is closed or waited for
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if abandoned, err := h.Scope().Shutdown(ctx); err != nil || len(abandoned) != 0 {
+		t.Fatalf("Expecting handle shut down, got %v: %v", abandoned, err)
+	}
+	if _, err := h.Add(func(ctx context.Context) error { return nil }); err != context.Canceled {
+		t.Fatalf("Expecting worker rejected by shut down group, got %v", err)
+	}
+	if err := h.Wait(); err != nil || h.Completed() != 2 {
+		t.Fatalf("Expecting 2 workers completed, got %d: %v", h.Completed(), err)
+	}
+}
@@ -0,0 +1,51 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+// Hedge returns a worker that calls the primary worker and, if it has
+// not completed within the duration, hedgeAfter, calls the first of the
+// backup workers, and so on, one backup for each further delay. When a
+// worker completes with an error, the next backup is called without
+// waiting for the delay. The first worker to complete without error
+// cancels the others, as for CancelOnFirstSuccess, and if all of the
+// workers complete with an error, then the first error is returned. This
+// reduces the tail latency of requests to replicated services, at the
+// cost of duplicate requests. The time is measured by the clock of the
+// context, see WithClock.
+func Hedge(primary Worker, hedgeAfter time.Duration, backups ...Worker) Worker {
+	return func(ctx context.Context) error {
+		failed := make(chan struct{}, len(backups)+1)
+		attempt := func(w Worker) Worker {
+			return func(ctx context.Context) error {
+				err := w(ctx)
+				if err != nil {
+					failed <- struct{}{}
+				}
+				return err
+			}
+		}
+
+		workers := make([]Worker, 0, len(backups)+1)
+		workers = append(workers, attempt(primary))
+		for i, b := range backups {
+			delay := time.Duration(i+1) * hedgeAfter
+			backup := attempt(b)
+			workers = append(workers, func(ctx context.Context) error {
+				timer := ClockFrom(ctx).NewTimer(delay)
+				defer timer.Stop()
+				select {
+				case <-timer.C():
+				case <-failed:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				return backup(ctx)
+			})
+		}
+
+		return Work(ctx, NewUnlimited(), CancelOnFirstSuccess(), workers...)
+	}
+}
@@ -0,0 +1,47 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge(t *testing.T) {
+
+	var calls int32
+	slow := func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	fast := func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	start := time.Now()
+	err := Hedge(slow, 10*time.Millisecond, fast, fast)(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Fatalf("Expecting backup started after delay, completed in %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("Expecting primary and one backup called, got %d", n)
+	}
+
+	failed := errors.New("failed")
+	start = time.Now()
+	err = Hedge(func(ctx context.Context) error { return failed }, time.Hour, fast)(context.Background())
+	if err != nil || time.Since(start) > time.Second {
+		t.Fatalf("Expecting backup started when primary failed: %v", err)
+	}
+
+	err = Hedge(func(ctx context.Context) error { return failed }, time.Hour)(context.Background())
+	if err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+}
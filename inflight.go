@@ -0,0 +1,51 @@
+package workgroup
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// inflight tracks the running workers of a work group.
+type inflight struct {
+	mutex   sync.Mutex
+	clock   Clock
+	workers map[int]time.Time
+}
+
+func newInflight(clock Clock) *inflight {
+	return &inflight{clock: clock, workers: make(map[int]time.Time)}
+}
+
+// start records the start of the worker with the given index
+// and returns a function to call when the worker completes.
+func (f *inflight) start(index int) (done func()) {
+	f.mutex.Lock()
+	f.workers[index] = f.clock.Now()
+	f.mutex.Unlock()
+
+	return func() {
+		f.mutex.Lock()
+		delete(f.workers, index)
+		f.mutex.Unlock()
+	}
+}
+
+func (f *inflight) dump() []WorkerInfo {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.clock.Now()
+	infos := make([]WorkerInfo, 0, len(f.workers))
+	for index, started := range f.workers {
+		infos = append(infos, WorkerInfo{
+			Index:   index,
+			Started: started,
+			Elapsed: now.Sub(started),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Index < infos[j].Index
+	})
+	return infos
+}
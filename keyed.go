@@ -0,0 +1,129 @@
+package workgroup
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+type keyKey struct{}
+
+type keyFuncKey struct{}
+
+// WithKey returns a copy of the context, ctx, that assigns the key to
+// functions executed with the context, see NewKeyedPool. The workers of
+// work groups started with the context are submitted with the key, unless
+// the context is configured by WithKeys.
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyKey{}, key)
+}
+
+// WithKeys returns a copy of the context, ctx, that configures the work
+// group started with it to submit each worker with the key returned by
+// the function, fn, for the index of the worker, see NewKeyedPool. The
+// workers of work groups nested in a worker have the key of that worker.
+func WithKeys(ctx context.Context, fn func(index int) string) context.Context {
+	return context.WithValue(ctx, keyFuncKey{}, fn)
+}
+
+func keyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(keyKey{}).(string)
+	return key, ok
+}
+
+// KeyedPool is an executer that executes functions with the same key, see
+// WithKey and WithKeys, serially in the order that they are submitted, and
+// functions with different keys in parallel, so that work on one entity is
+// ordered without serializing all of the work. Each shard of the pool is
+// served by one goroutine, and the key of a function selects its shard.
+// Functions without a key are spread over the shards. A function must not
+// wait for a function that is submitted after it with the same key, for
+// example by starting a nested work group with the key, or it deadlocks.
+type KeyedPool struct {
+	mutex  sync.RWMutex
+	shards []chan shardedTask
+	closed chan struct{}
+	next   uint32
+}
+
+// NewKeyedPool initializes a new keyed pool executer with the given number
+// of shards. If shards <= 0 then the value provided by DefaultLimit is used.
+// The pool executes functions in the calling goroutine once the context,
+// ctx, is done and the queued functions have been started. If the context
+// is nil, or is never done, then the pool is never closed.
+func NewKeyedPool(ctx context.Context, shards int) *KeyedPool {
+	if shards <= 0 {
+		shards = DefaultLimit
+	}
+	if shards <= 0 {
+		shards = runtime.NumCPU()
+	}
+
+	p := &KeyedPool{
+		shards: make([]chan shardedTask, shards),
+		closed: make(chan struct{}),
+	}
+	for i := range p.shards {
+		p.shards[i] = make(chan shardedTask, shardCapacity)
+		go p.run(p.shards[i])
+	}
+
+	if ctx != nil && ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			close(p.closed)
+		}()
+	}
+
+	return p
+}
+
+// Execute queues the function, f, on the shard selected by the key of
+// the context, ctx, and blocks while the shard is full. If the pool is
+// closed then the function is executed in the calling goroutine.
+func (p *KeyedPool) Execute(ctx context.Context, f func(context.Context)) {
+	p.mutex.RLock()
+	select {
+	case <-p.closed:
+		p.mutex.RUnlock()
+		f(ctx)
+		return
+	default:
+	}
+
+	p.shards[p.shard(ctx)] <- shardedTask{ctx: ctx, f: f}
+	p.mutex.RUnlock()
+}
+
+// shard returns the index of the shard for the key of the context, ctx.
+func (p *KeyedPool) shard(ctx context.Context) int {
+	key, ok := keyFrom(ctx)
+	if !ok {
+		return int(atomic.AddUint32(&p.next, 1) % uint32(len(p.shards)))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.shards)))
+}
+
+func (p *KeyedPool) run(shard chan shardedTask) {
+	for {
+		select {
+		case t := <-shard:
+			t.f(t.ctx)
+		case <-p.closed:
+			for {
+				select {
+				case t := <-shard:
+					t.f(t.ctx)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package workgroup
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestKeyedPool(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewKeyedPool(ctx, 4)
+
+	var mutex sync.Mutex
+	order := make(map[string][]int)
+
+	kctx := WithKeys(ctx, func(index int) string {
+		return "user-" + strconv.Itoa(index%3)
+	})
+	err := WorkFor(kctx, p, nil, 30, func(ctx context.Context, index int) error {
+		key, _ := keyFrom(ctx)
+		mutex.Lock()
+		defer mutex.Unlock()
+		order[key] = append(order[key], index)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(order) != 3 {
+		t.Fatalf("Expecting 3 keys, got %v", order)
+	}
+	for key, indexes := range order {
+		for i := 1; i < len(indexes); i++ {
+			if indexes[i] < indexes[i-1] {
+				t.Fatalf("Workers of key %s executed out of order: %v", key, indexes)
+			}
+		}
+	}
+}
+
+func TestKeyedPoolNilContext(t *testing.T) {
+
+	p := NewKeyedPool(nil, 2)
+
+	var count int32
+	WorkFor(WithKey(context.Background(), "user"), p, nil, 10, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	if count != 10 {
+		t.Fatalf("Expecting 10 workers executed, got %d", count)
+	}
+}
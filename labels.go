@@ -0,0 +1,14 @@
+package workgroup
+
+import "context"
+
+type labelKey struct{}
+
+// WithProfilerLabels returns a copy of the context, ctx, that configures
+// work groups started with it, and all work groups nested within them,
+// to run each worker under pprof.Do with the labels "workgroup", set to
+// the name, and "worker", set to the index of the worker, so that CPU
+// and goroutine profiles attribute time to specific groups and workers.
+func WithProfilerLabels(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, labelKey{}, name)
+}
@@ -0,0 +1,25 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestWithProfilerLabels(t *testing.T) {
+
+	ctx := WithProfilerLabels(context.Background(), "ingest")
+	err := WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+		if v, _ := pprof.Label(ctx, "workgroup"); v != "ingest" {
+			return fmt.Errorf("unexpected workgroup label: %q", v)
+		}
+		if v, _ := pprof.Label(ctx, "worker"); v != fmt.Sprint(index) {
+			return fmt.Errorf("unexpected worker label: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,57 @@
+package workgroup
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySamples is the number of the most recent
+// samples from which the latency percentiles are computed.
+const latencySamples = 1024
+
+// Latency contains percentiles of the scheduling latency, the time
+// between the submission of a worker and the start of the worker.
+type Latency struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// latencyRecorder records a window of the most recent latency samples.
+type latencyRecorder struct {
+	mutex   sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (r *latencyRecorder) record(d time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.samples) < latencySamples {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % latencySamples
+}
+
+// percentiles returns the percentiles of the recorded samples.
+func (r *latencyRecorder) percentiles() Latency {
+	r.mutex.Lock()
+	samples := make([]time.Duration, len(r.samples))
+	copy(samples, r.samples)
+	r.mutex.Unlock()
+
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	at := func(p int) time.Duration {
+		return samples[(len(samples)-1)*p/100]
+	}
+	return Latency{P50: at(50), P95: at(95), P99: at(99)}
+}
@@ -0,0 +1,37 @@
+package workgroup
+
+import (
+	"context"
+	"time"
+)
+
+type loggerKey struct{}
+
+// groupLogger logs the events of work groups, see WithLogger.
+type groupLogger interface {
+	// started logs the start of the worker with the given index.
+	started(ctx context.Context, index int)
+
+	// finished logs the completion of the worker with the given index
+	// after the duration, d, with the error, err, or a panic.
+	finished(ctx context.Context, index int, d time.Duration, err error, panicked bool)
+
+	// completed logs the completion of the work group
+	// after the duration, d, with the error, err.
+	completed(ctx context.Context, d time.Duration, err error)
+}
+
+// ranWorker returns a worker that calls the worker, w, and records
+// that it returned, rather than panicked, in the flag, ran.
+func ranWorker(w Worker, ran *bool) Worker {
+	return func(ctx context.Context) error {
+		err := w(ctx)
+		*ran = true
+		return err
+	}
+}
+
+func loggerFrom(ctx context.Context) groupLogger {
+	l, _ := ctx.Value(loggerKey{}).(groupLogger)
+	return l
+}
@@ -3,11 +3,141 @@ package workgroup
 import (
 	"context"
 	"errors"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // DefaultManager is a function that provides the default manager.
-var DefaultManager = CancelOnFirstError
+var DefaultManager = func() Manager {
+	return CancelOnFirstError()
+}
+
+// SetDefaultManager configures the function, fn, to provide the manager
+// of work groups started without a manager, see DefaultManager. A manager
+// holds the state of a single work group, so fn must return a new manager
+// each time it is called, for example, CancelNeverFirstError. It should
+// be called when the application is initialized, before work groups are
+// started. If fn is nil, then CancelOnFirstError is restored.
+func SetDefaultManager(fn func() Manager) {
+	if fn == nil {
+		fn = func() Manager {
+			return CancelOnFirstError()
+		}
+	}
+	DefaultManager = fn
+}
+
+// ManagerOption configures optional behavior of a manager.
+// Options that are not applicable to a manager are ignored.
+type ManagerOption func(*managerOptions)
+
+type managerOptions struct {
+	stack             bool
+	ignoreGroupCancel bool
+	maxErrors         int
+	classify          func(error) Severity
+	suppressCancel    bool
+	shards            int
+	maxPanics         int
+}
+
+func newManagerOptions(opts []ManagerOption) managerOptions {
+	var o managerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// IncludeStack configures the Recover and Repanic managers
+// to include the stack of the panicking goroutine in the
+// message returned by the Error method of PanicError.
+func IncludeStack() ManagerOption {
+	return func(o *managerOptions) {
+		o.stack = true
+	}
+}
+
+// WithMaxErrors configures the Accumulate manager to keep at most
+// n errors, the errors of successful workers are not kept and any
+// further errors are counted as dropped. If n <= 0 then the error
+// of every worker is kept, which is the default.
+func WithMaxErrors(n int) ManagerOption {
+	return func(o *managerOptions) {
+		o.maxErrors = n
+	}
+}
+
+// IgnoreGroupCancel configures the built-in managers to treat
+// errors that only reflect the cancellation of the work group
+// context as neutral. For example, when the group is cancelled
+// workers commonly return context.Canceled, these errors are not
+// counted as failures and do not trigger cancellation or become the
+// error of the work group. Errors from a worker's own deadline,
+// such as context.DeadlineExceeded, are still counted as failures.
+// If no other error occurs, then the first neutral error is the
+// error of the work group so that cancellation is still reported.
+func IgnoreGroupCancel() ManagerOption {
+	return func(o *managerOptions) {
+		o.ignoreGroupCancel = true
+	}
+}
+
+// CollectPanics configures the Recover and Repanic managers to keep the
+// panics of up to n workers, rather than only passing each panic to the
+// wrapped manager, which commonly keeps only the first error. If a worker
+// panicked, then the error of the work group is a PanicErrors that contains
+// the panics, and Repanic panics with it. If n <= 0, then the option is
+// ignored.
+func CollectPanics(n int) ManagerOption {
+	return func(o *managerOptions) {
+		o.maxPanics = n
+	}
+}
+
+// WithShards configures the Accumulate manager to record the errors in
+// n shards, selected by the index of the worker, so that the recording
+// of errors scales with the number of cores for large work groups. The
+// shards are merged when the errors are returned. If n <= 0 then the
+// number of shards is runtime.GOMAXPROCS. By default, one shard is used.
+func WithShards(n int) ManagerOption {
+	return func(o *managerOptions) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		o.shards = n
+	}
+}
+
+// SuppressCancel configures the Accumulate manager to count, but not
+// keep, the errors that only reflect the cancellation of the work group
+// context, commonly the context.Canceled returned by every worker that
+// completes after the first error, so that the kept errors are only the
+// errors that caused the failure. The number of suppressed errors is
+// returned by the Suppressed method, see IgnoreGroupCancel.
+func SuppressCancel() ManagerOption {
+	return func(o *managerOptions) {
+		o.suppressCancel = true
+	}
+}
+
+// cancelled reports whether the error, err, only
+// reflects the cancellation of the work group context, ctx.
+func cancelled(ctx context.Context, err error) bool {
+	cerr := ctx.Err()
+	return err != nil && cerr != nil && errors.Is(err, cerr)
+}
+
+// neutral reports whether the error, err, only reflects the
+// cancellation of the work group context, ctx, and is to be ignored.
+func (o *managerOptions) neutral(ctx context.Context, err error) bool {
+	return o.ignoreGroupCancel && cancelled(ctx, err)
+}
 
 // Canceller cancels the work context.
 type Canceller interface {
@@ -22,6 +152,11 @@ func (c CancellerFunc) Cancel() {
 	c()
 }
 
+// noCancel is a canceller that does not cancel, see RecoverContinue.
+type noCancel struct{}
+
+func (noCancel) Cancel() {}
+
 // Manager provides an interface for management of a work group.
 type Manager interface {
 	// Manage controls the cancellation and overall error
@@ -34,176 +169,687 @@ type Manager interface {
 	Error() error
 }
 
+// InfoManager is an optional extension of the Manager interface for
+// managers that manage the work group with the metadata of each worker,
+// such as its duration, rather than only its index. If the manager of
+// a work group, or the manager wrapped by Recover or Repanic, implements
+// this interface, then ManageInfo is called instead of Manage.
+type InfoManager interface {
+	Manager
+
+	// ManageInfo is the same as Manage, except that
+	// it is provided the metadata of the worker.
+	ManageInfo(ctx context.Context, c Canceller, info WorkerInfo, err *error) int
+}
+
+// NonCancelling is an optional extension of the Manager interface for
+// managers that never cancel the work group context. If the manager of
+// a work group reports that it never cancels, and the context of the
+// group is configured by WithSharedContext, then the work group does not
+// create a cancellable context for its workers. The built-in wrappers
+// report whether the manager that they wrap never cancels.
+type NonCancelling interface {
+	Manager
+
+	// NeverCancels reports whether the manager never
+	// calls the Cancel method of the Canceller.
+	NeverCancels() bool
+}
+
+// neverCancels reports whether the manager, m, never cancels.
+func neverCancels(m Manager) bool {
+	nc, ok := m.(NonCancelling)
+	return ok && nc.NeverCancels()
+}
+
+type sharedContextKey struct{}
+
+// WithSharedContext returns a copy of the context, ctx, that configures
+// the work groups started with it, and all work groups nested within
+// them, whose manager never cancels, see NonCancelling, to pass the
+// context to their workers without creating a cancellable context, which
+// is cheaper for small work groups that are started frequently. The
+// context of the workers of such a group is not cancelled when the group
+// completes, so the workers must not leave goroutines that wait for it,
+// and Scope.Shutdown does not cancel the running workers. By default the
+// context of the workers is cancelled when the group completes.
+func WithSharedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sharedContextKey{}, true)
+}
+
+// infoManager returns the manager, m, or the manager wrapped
+// by Recover or Repanic, if it implements InfoManager.
+func infoManager(m Manager) InfoManager {
+	if w, ok := m.(*recoverWrapper); ok {
+		m = w.m
+	}
+	im, _ := m.(InfoManager)
+	return im
+}
+
+// errorSlot holds the first error that is stored in it, which may be nil,
+// it is lock-free so that managers do not serialize completed workers.
+type errorSlot struct {
+	v atomic.Value
+}
+
+type errorBox struct {
+	err error
+}
+
+// store stores the error, err, if no error has been
+// stored and reports whether it was stored.
+func (s *errorSlot) store(err error) bool {
+	if s.v.Load() != nil {
+		return false
+	}
+	return s.v.CompareAndSwap(nil, errorBox{err})
+}
+
+// load returns the stored error, or nil if no error has been stored.
+func (s *errorSlot) load() error {
+	b, _ := s.v.Load().(errorBox)
+	return b.err
+}
+
 type firstError struct {
-	mutex     sync.Mutex
-	ncomplete int
-	nerror    int
-	err       error
+	opts      managerOptions
+	ncomplete int64
+	cancelled int32
+	err       errorSlot
+	neutral   errorSlot
 }
 
 // CancelOnFirstError initilizes a manager that
 // cancels the work group context when a worker
 // completes with an error.
-func CancelOnFirstError() Manager {
-	return &firstError{}
+func CancelOnFirstError(opts ...ManagerOption) Manager {
+	return &firstError{opts: newManagerOptions(opts)}
 }
 
 func (m *firstError) Error() error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	return m.err
+	if err := m.err.load(); err != nil {
+		return err
+	}
+	return m.neutral.load()
 }
 
 func (m *firstError) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	m.ncomplete++
-	if *err != nil {
-		m.nerror++
-		if m.nerror == 1 {
-			m.err = *err
-			c.Cancel()
+	switch severity := m.opts.severity(ctx, *err); {
+	case severity == SeverityIgnorable:
+		m.neutral.store(*err)
+	case *err != nil:
+		m.err.store(*err)
+		if severity == SeverityDefault || severity == SeverityFatal {
+			m.cancel(c)
 		}
 	}
 
-	return m.ncomplete
+	return int(atomic.AddInt64(&m.ncomplete, 1))
+}
+
+// cancel cancels the work group once, the canceller of a worker
+// that does not cancel the work group, see RecoverContinue, does
+// not count.
+func (m *firstError) cancel(c Canceller) {
+	if _, ok := c.(noCancel); ok {
+		return
+	}
+	if atomic.LoadInt32(&m.cancelled) == 0 && atomic.CompareAndSwapInt32(&m.cancelled, 0, 1) {
+		c.Cancel()
+	}
 }
 
 type firstSuccess struct {
-	mutex    sync.Mutex
-	nsuccess int
-	nerror   int
-	err      error
+	opts      managerOptions
+	ncomplete int64
+	succeeded int32
+	err       errorSlot
+	neutral   errorSlot
 }
 
 // CancelOnFirstSuccess initializes a manager that
 // that cancels the work group context when a worker
 // completes without error. If all workers complete
 // with an error, then the first error is returned.
-func CancelOnFirstSuccess() Manager {
-	return &firstSuccess{}
+func CancelOnFirstSuccess(opts ...ManagerOption) Manager {
+	return &firstSuccess{opts: newManagerOptions(opts)}
 }
 
 func (m *firstSuccess) Error() error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	return m.err
+	if atomic.LoadInt32(&m.succeeded) != 0 {
+		return nil
+	}
+	if err := m.err.load(); err != nil {
+		return err
+	}
+	return m.neutral.load()
 }
 
 func (m *firstSuccess) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	if *err != nil {
-		m.nerror++
-		if m.nerror == 1 && m.nsuccess == 0 {
-			m.err = *err
+	switch severity := m.opts.severity(ctx, *err); {
+	case severity == SeverityIgnorable:
+		m.neutral.store(*err)
+	case *err != nil:
+		m.err.store(*err)
+		if severity == SeverityFatal {
+			c.Cancel()
 		}
-	} else {
-		m.nsuccess++
-		if m.nsuccess == 1 {
-			m.err = nil
+	default:
+		if atomic.LoadInt32(&m.succeeded) == 0 && atomic.CompareAndSwapInt32(&m.succeeded, 0, 1) {
 			c.Cancel()
 		}
 	}
 
-	return m.nsuccess + m.nerror
+	return int(atomic.AddInt64(&m.ncomplete, 1))
 }
 
 type firstDone struct {
-	mutex     sync.Mutex
-	ncomplete int
-	result    error
+	opts      managerOptions
+	ncomplete int64
+	result    errorSlot
+	neutral   errorSlot
 }
 
 // CancelOnFirstComplete initializes a new manager that
 // cancels the work group context when a worker completes
 // with or with an error.
-func CancelOnFirstComplete() Manager {
-	return &firstDone{}
+func CancelOnFirstComplete(opts ...ManagerOption) Manager {
+	return &firstDone{opts: newManagerOptions(opts)}
 }
 
 func (m *firstDone) Error() error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	return m.result
+	if v := m.result.v.Load(); v != nil {
+		return v.(errorBox).err
+	}
+	return m.neutral.load()
 }
 
 func (m *firstDone) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	m.ncomplete++
-	if m.ncomplete == 1 {
-		m.result = *err
+	if m.opts.severity(ctx, *err) == SeverityIgnorable {
+		m.neutral.store(*err)
+	} else if m.result.store(*err) {
 		c.Cancel()
 	}
 
-	return m.ncomplete
+	return int(atomic.AddInt64(&m.ncomplete, 1))
 }
 
 type neverFirstError struct {
-	mutex     sync.Mutex
-	ncomplete int
-	err       error
+	opts      managerOptions
+	ncomplete int64
+	err       errorSlot
+	neutral   errorSlot
 }
 
 // CancelNeverFirstError initializes a new manager that never
 // cancels the work group context, but will return the error
 // from the first worker that completes with an error.
-func CancelNeverFirstError() Manager {
-	return &neverFirstError{}
+func CancelNeverFirstError(opts ...ManagerOption) Manager {
+	return &neverFirstError{opts: newManagerOptions(opts)}
+}
+
+// NeverCancels reports whether the manager never cancels,
+// which is the case unless it is configured by WithClassifier.
+func (m *neverFirstError) NeverCancels() bool {
+	return m.opts.classify == nil
 }
 
 func (m *neverFirstError) Error() error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	return m.err
+	if err := m.err.load(); err != nil {
+		return err
+	}
+	return m.neutral.load()
 }
 
 func (m *neverFirstError) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	switch severity := m.opts.severity(ctx, *err); {
+	case severity == SeverityIgnorable:
+		m.neutral.store(*err)
+	case *err != nil:
+		m.err.store(*err)
+		if severity == SeverityFatal {
+			c.Cancel()
+		}
+	}
+
+	return int(atomic.AddInt64(&m.ncomplete, 1))
+}
+
+// AccumulateManager is a manager that wraps another
+// manager and records the error of every worker as
+// it completes. The wrapped manager remains responsible
+// for cancellation and the final error of the work group.
+type AccumulateManager struct {
+	m          Manager
+	opts       managerOptions
+	shards     []accumulateShard
+	ncomplete  int64
+	kept       int64
+	dropped    int64
+	suppressed int64
+}
+
+// accumulateShard records the errors of the workers whose index
+// maps to the shard, it is padded to avoid false sharing.
+type accumulateShard struct {
+	mutex   sync.Mutex
+	errors  []accumulated
+	indexed map[int]error
+	_       [24]byte
+}
+
+// accumulated is an error with the number of workers
+// that had completed when it was recorded.
+type accumulated struct {
+	n   int
+	err error
+}
+
+// Accumulate initializes a new manager that wraps the
+// manager, m, and records the error of each completed
+// worker. If manager, m, is not provided then DefaultManager
+// is called to obtain the default manager. Note that the
+// Recover and Repanic wrappers must be the outermost
+// manager, so they should wrap this manager and not
+// the reverse. The number of errors that are kept can
+// be bounded with the WithMaxErrors option, the errors
+// of cancellation can be left out with SuppressCancel,
+// and the errors can be recorded in shards with WithShards.
+func Accumulate(m Manager, opts ...ManagerOption) *AccumulateManager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	o := newManagerOptions(opts)
+	shards := o.shards
+	if shards < 1 {
+		shards = 1
+	}
+	a := &AccumulateManager{
+		m:      m,
+		opts:   o,
+		shards: make([]accumulateShard, shards),
+	}
+	for i := range a.shards {
+		a.shards[i].indexed = make(map[int]error)
+	}
+	return a
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (m *AccumulateManager) NeverCancels() bool {
+	return neverCancels(m.m)
+}
+
+func (m *AccumulateManager) Error() error {
+	return m.m.Error()
+}
+
+func (m *AccumulateManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := m.m.Manage(ctx, c, idx, err)
+	for {
+		ncomplete := atomic.LoadInt64(&m.ncomplete)
+		if int64(n) <= ncomplete || atomic.CompareAndSwapInt64(&m.ncomplete, ncomplete, int64(n)) {
+			break
+		}
+	}
+
+	e := *err
+	if m.opts.suppressCancel && cancelled(ctx, e) {
+		atomic.AddInt64(&m.suppressed, 1)
+		e = nil
+	}
+	if e == nil {
+		return n
+	}
+	if m.opts.maxErrors > 0 && atomic.AddInt64(&m.kept, 1) > int64(m.opts.maxErrors) {
+		atomic.AddInt64(&m.dropped, 1)
+		return n
+	}
+
+	s := m.shard(idx)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.errors = append(s.errors, accumulated{n: n, err: e})
+	s.indexed[idx] = e
+
+	return n
+}
+
+// shard returns the shard of the worker with index, idx.
+func (m *AccumulateManager) shard(idx int) *accumulateShard {
+	if idx < 0 {
+		idx = -idx
+	}
+	return &m.shards[idx%len(m.shards)]
+}
+
+// Dropped returns the number of errors that were not kept
+// because of the limit configured by WithMaxErrors.
+func (m *AccumulateManager) Dropped() int {
+	return int(atomic.LoadInt64(&m.dropped))
+}
+
+// Suppressed returns the number of errors that were
+// not kept because of the SuppressCancel option.
+func (m *AccumulateManager) Suppressed() int {
+	return int(atomic.LoadInt64(&m.suppressed))
+}
+
+// Errors returns a copy of the errors of the completed
+// workers in the order that the workers completed.
+// The error of a worker that completed successfully, or
+// whose error was suppressed by SuppressCancel, is nil.
+// If the errors are bounded by WithMaxErrors, then only
+// the errors that were kept are returned.
+func (m *AccumulateManager) Errors() []error {
+	var all []accumulated
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mutex.Lock()
+		all = append(all, s.errors...)
+		s.mutex.Unlock()
+	}
+
+	if m.opts.maxErrors > 0 {
+		sort.Slice(all, func(i, j int) bool {
+			return all[i].n < all[j].n
+		})
+		errors := make([]error, len(all))
+		for i, a := range all {
+			errors[i] = a.err
+		}
+		return errors
+	}
+
+	errors := make([]error, atomic.LoadInt64(&m.ncomplete))
+	for _, a := range all {
+		if a.n > len(errors) {
+			errors = append(errors, make([]error, a.n-len(errors))...)
+		}
+		errors[a.n-1] = a.err
+	}
+	return errors
+}
+
+// ErrorAt returns the error of the worker with index, i.
+// The result is nil if the worker has not completed
+// or if it completed successfully.
+func (m *AccumulateManager) ErrorAt(i int) error {
+	s := m.shard(i)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.indexed[i]
+}
+
+// GroupError returns the errors that were kept by their worker index
+// as a GroupError, the result is nil if no worker completed with an error.
+func (m *AccumulateManager) GroupError() *GroupError {
+	indexed := make(map[int]error)
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mutex.Lock()
+		for idx, err := range s.indexed {
+			indexed[idx] = err
+		}
+		s.mutex.Unlock()
+	}
+	if len(indexed) == 0 {
+		return nil
+	}
+	return newGroupError(indexed)
+}
+
+// Hooks contains optional callbacks that are invoked as
+// workers complete. The callbacks may be called concurrently
+// from multiple goroutines.
+type Hooks struct {
+	// OnSuccess is called when a worker completes without error.
+	OnSuccess func(idx int)
+
+	// OnError is called when a worker completes with an error.
+	OnError func(idx int, err error)
+
+	// OnComplete is called when a worker completes
+	// with or without an error.
+	OnComplete func(idx int)
+}
+
+type observer struct {
+	m     Manager
+	hooks Hooks
+}
+
+// Observe wraps a Manager, m, and invokes the provided hooks
+// after the wrapped manager has handled each completed worker.
+// If manager, m, is not provided then DefaultManager is called
+// to obtain the default manager.
+func Observe(m Manager, hooks Hooks) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &observer{m: m, hooks: hooks}
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (o *observer) NeverCancels() bool {
+	return neverCancels(o.m)
+}
+
+func (o *observer) Error() error {
+	return o.m.Error()
+}
+
+func (o *observer) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := o.m.Manage(ctx, c, idx, err)
 
-	m.ncomplete++
 	if *err != nil {
-		if m.err == nil {
-			m.err = *err
+		if o.hooks.OnError != nil {
+			o.hooks.OnError(idx, *err)
+		}
+	} else {
+		if o.hooks.OnSuccess != nil {
+			o.hooks.OnSuccess(idx)
 		}
 	}
+	if o.hooks.OnComplete != nil {
+		o.hooks.OnComplete(idx)
+	}
+
+	return n
+}
+
+// WorkerError is an error that annotates the error of a worker with
+// the index and the optional names of the worker and its work group.
+type WorkerError struct {
+	Index int
+	Name  string
+	Group string
+	Err   error
+}
+
+func (e *WorkerError) Error() string {
+	msg := "worker " + strconv.Itoa(e.Index) + ": " + e.Err.Error()
+	if e.Name != "" {
+		msg = "worker " + e.Name + "[" + strconv.Itoa(e.Index) + "]: " + e.Err.Error()
+	}
+	if e.Group != "" {
+		msg = "group " + e.Group + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the original error of the worker.
+func (e *WorkerError) Unwrap() error {
+	return e.Err
+}
+
+type annotateWrapper struct {
+	m Manager
+}
+
+// AnnotateIndex wraps a Manager, m, and annotates the error
+// of each worker that completes with an error as a WorkerError
+// before it is passed to the wrapped manager. If manager, m,
+// is not provided then DefaultManager is called to obtain
+// the default manager.
+func AnnotateIndex(m Manager) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &annotateWrapper{m: m}
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (w *annotateWrapper) NeverCancels() bool {
+	return neverCancels(w.m)
+}
+
+func (w *annotateWrapper) Error() error {
+	return w.m.Error()
+}
 
-	return m.ncomplete
+func (w *annotateWrapper) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	if we, ok := (*err).(*WorkerError); ok && we.Index == idx {
+		// already annotated, see Named and WithGroupName
+	} else if *err != nil {
+		*err = &WorkerError{Index: idx, Err: *err}
+	}
+	return w.m.Manage(ctx, c, idx, err)
 }
 
 // PanicError is an error that represents a recovered panic
-// and contains the value returned from a call to recover.
+// and contains the value returned from a call to recover
+// along with the stack of the goroutine that panicked.
 type PanicError struct {
-	Value interface{}
+	value     interface{}
+	stack     []byte
+	withStack bool
 }
 
 func (e *PanicError) Error() string {
-	switch v := e.Value.(type) {
+	var msg string
+	switch v := e.value.(type) {
 	case string:
-		return "panic: " + v
+		msg = "panic: " + v
+	case error:
+		msg = "panic: " + v.Error()
 	case interface{ String() string }:
-		return "panic: " + v.String()
+		msg = "panic: " + v.String()
 	default:
-		return "panic: unknown"
+		msg = "panic: unknown"
+	}
+	if e.withStack && len(e.stack) > 0 {
+		msg += "\n\n" + string(e.stack)
+	}
+	return msg
+}
+
+// Value returns the value that was passed to panic.
+func (e *PanicError) Value() interface{} {
+	return e.value
+}
+
+// Unwrap returns the panic value if it is an error,
+// otherwise the result is nil.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Stack returns the stack of the goroutine
+// captured at the time of the panic.
+func (e *PanicError) Stack() []byte {
+	return e.stack
+}
+
+// PanicErrors is an error that contains the panics of the workers of
+// a work group, each as a WorkerError of a PanicError, see CollectPanics.
+type PanicErrors struct {
+	// Panics are the panics of the workers, in the order that they occurred.
+	Panics []*WorkerError
+
+	// Dropped is the number of panics that were not kept.
+	Dropped int
+}
+
+func (e *PanicErrors) Error() string {
+	msgs := make([]string, len(e.Panics))
+	for i, p := range e.Panics {
+		msgs[i] = p.Error()
+	}
+	msg := strconv.Itoa(len(e.Panics)+e.Dropped) + " panics: " + strings.Join(msgs, "; ")
+	if e.Dropped > 0 {
+		msg += "; and " + strconv.Itoa(e.Dropped) + " more"
+	}
+	return msg
+}
+
+// Unwrap returns the panics of the workers, which
+// errors.Is and errors.As match since Go 1.20.
+func (e *PanicErrors) Unwrap() []error {
+	errs := make([]error, len(e.Panics))
+	for i, p := range e.Panics {
+		errs[i] = p
 	}
+	return errs
+}
+
+// Is reports whether any of the panics of the workers matches the
+// error, target, so that errors.Is matches them before Go 1.20.
+func (e *PanicErrors) Is(target error) bool {
+	for _, p := range e.Panics {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the panics of the workers that matches the
+// target, and if so, sets the target to that error and returns true,
+// so that errors.As matches them before Go 1.20.
+func (e *PanicErrors) As(target interface{}) bool {
+	for _, p := range e.Panics {
+		if errors.As(p, target) {
+			return true
+		}
+	}
+	return false
 }
 
 type recoverWrapper struct {
-	p bool
-	m Manager
+	p    bool
+	cont bool
+	m    Manager
+	opts managerOptions
+
+	mutex   sync.Mutex
+	panics  []*WorkerError
+	dropped int
 }
 
 // Recover wraps a Manager, m, and if a worker
 // panics during execution this wrapper will
 // recover and create an instance of PanicError
 // which will passed to the wrapped manager.
-func Recover(m Manager) Manager {
-	return &recoverWrapper{m: m, p: false}
+// This wrapper must be the outermost manager
+// to be able to recover from the panic.
+func Recover(m Manager, opts ...ManagerOption) Manager {
+	return &recoverWrapper{m: m, p: false, opts: newManagerOptions(opts)}
+}
+
+// RecoverContinue is the same as Recover, except that a panic of a worker
+// does not cancel the work group, so that one malformed input of a batch
+// does not abort the other workers. The PanicError is passed to the wrapped
+// manager annotated with the index of the worker as a WorkerError, and may
+// become the error of the work group. Note that a manager that cancels on
+// the first completion, such as CancelOnFirstComplete, does not cancel
+// when the first worker to complete panicked.
+func RecoverContinue(m Manager, opts ...ManagerOption) Manager {
+	return &recoverWrapper{m: m, cont: true, opts: newManagerOptions(opts)}
 }
 
 // Repanic wraps a Manager, m, and if a worker
@@ -212,25 +858,100 @@ func Recover(m Manager) Manager {
 // which will be passed to the wrapped manager.
 // If the result of the wrapped manager is
 // an instance of PanicError, then this wapper
-// will panic when accessing the result.
-func Repanic(m Manager) Manager {
-	return &recoverWrapper{m: m, p: true}
+// will panic when accessing the result. The
+// value of the panic is the PanicError, so the
+// original value and stack are available with
+// its Value and Stack methods, and its message
+// includes the stack of the goroutine of the
+// worker, so that a crash report points to
+// the location of the original panic.
+func Repanic(m Manager, opts ...ManagerOption) Manager {
+	return &recoverWrapper{m: m, p: true, opts: newManagerOptions(opts)}
+}
+
+// NeverCancels reports whether the wrapped manager never cancels.
+func (w *recoverWrapper) NeverCancels() bool {
+	return neverCancels(w.m)
 }
 
 func (w *recoverWrapper) Error() error {
 	err := w.m.Error()
+	if w.opts.maxPanics > 0 {
+		w.mutex.Lock()
+		if len(w.panics) > 0 {
+			err = &PanicErrors{Panics: w.panics, Dropped: w.dropped}
+		}
+		w.mutex.Unlock()
+		if perrs, ok := err.(*PanicErrors); ok && w.p {
+			panic(perrs)
+		}
+	}
 	if w.p {
 		var perr *PanicError
 		if errors.As(err, &perr) {
-			panic(perr.Value)
+			panic(&PanicError{value: perr.value, stack: perr.stack, withStack: true})
 		}
 	}
 	return err
 }
 
+func (w *recoverWrapper) admit(ctx context.Context, idx int) error {
+	if a, ok := w.m.(admitter); ok {
+		return a.admit(ctx, idx)
+	}
+	return nil
+}
+
 func (w *recoverWrapper) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
 	if v := recover(); v != nil {
-		*err = &PanicError{Value: v}
+		*err = w.recovered(ctx, idx, v)
+		c = w.canceller(c)
 	}
 	return w.m.Manage(ctx, c, idx, err)
 }
+
+// canceller returns the canceller, c, for the wrapped manager
+// to manage a panic, which does not cancel for RecoverContinue.
+func (w *recoverWrapper) canceller(c Canceller) Canceller {
+	if w.cont {
+		return noCancel{}
+	}
+	return c
+}
+
+// recovered returns the error of the worker with
+// the given index that panicked with the value, v.
+func (w *recoverWrapper) recovered(ctx context.Context, idx int, v interface{}) error {
+	var err error = &PanicError{
+		value:     v,
+		stack:     debug.Stack(),
+		withStack: w.opts.stack,
+	}
+	if g, ok := ctx.Value(groupKey{}).(*group); ok {
+		err = g.annotate(idx, err)
+	}
+	if _, ok := err.(*WorkerError); !ok && w.cont {
+		err = &WorkerError{Index: idx, Err: err}
+	}
+	if w.opts.maxPanics > 0 {
+		w.collect(idx, err)
+	}
+	return err
+}
+
+// collect keeps the error, err, of the panic of the worker
+// with the given index, up to the limit of CollectPanics.
+func (w *recoverWrapper) collect(idx int, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.panics) >= w.opts.maxPanics {
+		w.dropped++
+		return
+	}
+	we, ok := err.(*WorkerError)
+	if !ok {
+		we = &WorkerError{Index: idx, Err: err}
+	}
+	w.panics = append(w.panics, we)
+}
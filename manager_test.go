@@ -2,30 +2,673 @@ package workgroup
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
 )
 
-// AccumulateManager is used only for testing (for now)
-type AccumulateManager struct {
-	mutex   sync.Mutex
-	manager Manager
-	Errors  []error
+func TestAccumulateManager(t *testing.T) {
+
+	counts := make([]int, 10000)
+
+	m := Accumulate(CancelNeverFirstError())
+
+	err := WorkFor(context.Background(), NewUnlimited(), m, len(counts),
+		func(ctx context.Context, index int) error {
+			time.Sleep(time.Millisecond)
+			counts[index]++
+
+			if index%2 == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	if err == nil {
+		t.Fatal("Work group error is nil")
+	}
+	if n := len(m.Errors()); n != len(counts) {
+		t.Fatalf("Expecting %d accumulated errors, got %d", len(counts), n)
+	}
+	for i := range counts {
+		e := m.ErrorAt(i)
+		if i%2 == 1 {
+			if e == nil || e.Error() != fmt.Sprintf("worker %d failed", i) {
+				t.Fatalf("Expecting accumulated error (%d) to be worker error: %v", i, e)
+			}
+		} else if e != nil {
+			t.Fatalf("Expecting accumulated error (%d) to be nil: %s", i, e)
+		}
+	}
+}
+
+func TestObserveManager(t *testing.T) {
+
+	var mutex sync.Mutex
+	var nsuccess, nerror, ncomplete int
+
+	m := Observe(CancelNeverFirstError(), Hooks{
+		OnSuccess: func(idx int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			nsuccess++
+		},
+		OnError: func(idx int, err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err.Error() != fmt.Sprintf("worker %d failed", idx) {
+				t.Errorf("Unexpected error for worker %d: %s", idx, err)
+			}
+			nerror++
+		},
+		OnComplete: func(idx int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			ncomplete++
+		},
+	})
+
+	WorkFor(context.Background(), NewUnlimited(), m, 1000,
+		func(ctx context.Context, index int) error {
+			if index < 100 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	if nsuccess != 900 || nerror != 100 || ncomplete != 1000 {
+		t.Fatalf("Unexpected hook counts: success=%d error=%d complete=%d", nsuccess, nerror, ncomplete)
+	}
+}
+
+func TestRecoverStack(t *testing.T) {
+
+	for _, include := range []bool{false, true} {
+		var opts []ManagerOption
+		if include {
+			opts = append(opts, IncludeStack())
+		}
+
+		err := Work(context.Background(), nil, Recover(CancelOnFirstError(), opts...),
+			func(ctx context.Context) error {
+				panicWorker()
+				return nil
+			},
+		)
+
+		var perr *PanicError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Work group error is not a PanicError: %v", err)
+		}
+		if !strings.Contains(string(perr.Stack()), "panicWorker") {
+			t.Fatalf("PanicError stack does not contain panicking function:\n%s", perr.Stack())
+		}
+		if strings.Contains(perr.Error(), "panicWorker") != include {
+			t.Fatalf("PanicError message stack included is not %v: %s", include, perr.Error())
+		}
+	}
+}
+
+func panicWorker() {
+	panic("worker failed")
+}
+
+func TestIgnoreGroupCancel(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	workers := []Worker{
+		func(ctx context.Context) error {
+			return ctx.Err()
+		},
+		func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return context.DeadlineExceeded
+		},
+	}
+
+	err := Work(ctx, nil, CancelNeverFirstError(), workers...)
+	if err != context.Canceled {
+		t.Fatalf("Expecting work group error to be canceled: %v", err)
+	}
+
+	err = Work(ctx, nil, CancelNeverFirstError(IgnoreGroupCancel()), workers...)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expecting work group error to be deadline exceeded: %v", err)
+	}
+
+	err = Work(ctx, nil, CancelOnFirstSuccess(IgnoreGroupCancel()), workers[0])
+	if err != context.Canceled {
+		t.Fatalf("Expecting neutral work group error to be canceled: %v", err)
+	}
+}
+
+var (
+	errInvalid      = errors.New("invalid")
+	errUnauthorized = errors.New("unauthorized")
+	errTransient    = errors.New("transient")
+)
+
+func classifyTest(err error) Severity {
+	switch err {
+	case errInvalid:
+		return SeverityRecorded
+	case errUnauthorized:
+		return SeverityFatal
+	case errTransient:
+		return SeverityRetryable
+	case context.Canceled:
+		return SeverityIgnorable
+	}
+	return SeverityDefault
+}
+
+func TestWithClassifier(t *testing.T) {
+
+	// recorded errors do not cancel
+	var cancelled int32
+	err := WorkFor(context.Background(), NewSerial(), CancelOnFirstError(WithClassifier(classifyTest)), 3, func(ctx context.Context, index int) error {
+		if ctx.Err() != nil {
+			atomic.AddInt32(&cancelled, 1)
+		}
+		return errInvalid
+	})
+	if err != errInvalid || cancelled != 0 {
+		t.Fatalf("Expecting recorded error without cancellation, got %d: %v", cancelled, err)
+	}
+
+	// fatal errors cancel managers that do not otherwise cancel
+	err = WorkFor(context.Background(), NewSerial(), CancelNeverFirstError(WithClassifier(classifyTest)), 3, func(ctx context.Context, index int) error {
+		if ctx.Err() != nil {
+			atomic.AddInt32(&cancelled, 1)
+			return ctx.Err()
+		}
+		if index == 1 {
+			return errUnauthorized
+		}
+		return errInvalid
+	})
+	if err != errInvalid || cancelled != 1 {
+		t.Fatalf("Expecting cancellation with first error as the work group error, got %d: %v", cancelled, err)
+	}
+
+	// ignorable errors are neutral
+	err = WorkFor(context.Background(), NewSerial(), CancelOnFirstError(WithClassifier(classifyTest)), 2, func(ctx context.Context, index int) error {
+		if index == 0 {
+			return context.Canceled
+		}
+		return errUnauthorized
+	})
+	if err != errUnauthorized {
+		t.Fatalf("Expecting fatal error to be the work group error: %v", err)
+	}
+}
+
+type panicValueError struct {
+	code int
+}
+
+func (e *panicValueError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestPanicErrorUnwrap(t *testing.T) {
+
+	err := Work(context.Background(), nil, Recover(CancelOnFirstError()),
+		func(ctx context.Context) error {
+			panic(&panicValueError{code: 42})
+		},
+	)
+
+	var verr *panicValueError
+	if !errors.As(err, &verr) || verr.code != 42 {
+		t.Fatalf("Work group error does not unwrap to panic value: %v", err)
+	}
+	if err.Error() != "panic: code 42" {
+		t.Fatalf("Work group error message incorrect: %s", err)
+	}
+
+	err = Work(context.Background(), nil, Recover(CancelOnFirstError()),
+		func(ctx context.Context) error {
+			panic(42)
+		},
+	)
+
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value() != 42 || perr.Unwrap() != nil {
+		t.Fatalf("Work group error does not contain panic value: %v", err)
+	}
+}
+
+func TestAnnotateIndex(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	err := WorkFor(context.Background(), nil, AnnotateIndex(CancelOnFirstError()), 10,
+		func(ctx context.Context, index int) error {
+			if index == 7 {
+				return failed
+			}
+			return nil
+		},
+	)
+
+	if err == nil || err.Error() != "worker 7: failed" {
+		t.Fatalf("Work group error is not annotated: %v", err)
+	}
+	if errors.Unwrap(err) != failed {
+		t.Fatalf("Work group error does not unwrap to original error")
+	}
+}
+
+func TestAccumulateMaxErrors(t *testing.T) {
+
+	m := Accumulate(CancelNeverFirstError(), WithMaxErrors(10))
+
+	WorkFor(context.Background(), nil, m, 1000,
+		func(ctx context.Context, index int) error {
+			if index%2 == 1 {
+				return fmt.Errorf("worker %d failed", index)
+			}
+			return nil
+		},
+	)
+
+	errs := m.Errors()
+	if len(errs) != 10 {
+		t.Fatalf("Expecting 10 accumulated errors, got %d", len(errs))
+	}
+	for _, e := range errs {
+		if e == nil {
+			t.Fatalf("Expecting accumulated errors to not be nil")
+		}
+	}
+	if n := m.Dropped(); n != 490 {
+		t.Fatalf("Expecting 490 dropped errors, got %d", n)
+	}
+}
+
+func TestAccumulateShards(t *testing.T) {
+
+	m := Accumulate(CancelNeverFirstError(), WithShards(4))
+	WorkFor(context.Background(), nil, m, 100, func(ctx context.Context, index int) error {
+		if index%10 == 0 {
+			return fmt.Errorf("worker %d failed", index)
+		}
+		return nil
+	})
+
+	errs := m.Errors()
+	nerrs := 0
+	for _, e := range errs {
+		if e != nil {
+			nerrs++
+		}
+	}
+	if len(errs) != 100 || nerrs != 10 {
+		t.Fatalf("Expecting 100 accumulated errors with 10 failures, got %d and %d", len(errs), nerrs)
+	}
+	if m.ErrorAt(30) == nil || m.ErrorAt(31) != nil || m.GroupError().Len() != 10 {
+		t.Fatalf("Expecting errors by index from the shards")
+	}
+
+	m = Accumulate(CancelNeverFirstError(), WithShards(0), WithMaxErrors(5))
+	WorkFor(context.Background(), nil, m, 100, func(ctx context.Context, index int) error {
+		return fmt.Errorf("worker %d failed", index)
+	})
+	if n := len(m.Errors()); n != 5 || m.Dropped() != 95 {
+		t.Fatalf("Expecting 5 kept and 95 dropped errors, got %d and %d", n, m.Dropped())
+	}
+}
+
+func TestAccumulateSuppressCancel(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	m := Accumulate(CancelOnFirstError(), SuppressCancel())
+	WorkFor(context.Background(), NewSerial(), m, 5, func(ctx context.Context, index int) error {
+		if index == 1 {
+			return failed
+		}
+		return ctx.Err()
+	})
+
+	errs := m.Errors()
+	if len(errs) != 5 || errs[1] != failed || m.ErrorAt(3) != nil {
+		t.Fatalf("Expecting only the error of worker 1 kept: %v", errs)
+	}
+	if n := m.Suppressed(); n != 3 {
+		t.Fatalf("Expecting 3 suppressed errors, got %d", n)
+	}
+}
+
+// workerError is the error of a worker with its index.
+type workerError struct {
+	index int
+	err   error
+}
+
+func (e *workerError) Error() string {
+	return fmt.Sprintf("worker %d: %v", e.index, e.err)
 }
 
-func (m *AccumulateManager) Error() error {
-	return m.manager.Error()
+func (e *workerError) Unwrap() error {
+	return e.err
 }
 
-func (m *AccumulateManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
-	n := m.manager.Manage(ctx, c, idx, err)
+func TestAccumulateGroupError(t *testing.T) {
 
+	failed := errors.New("failed")
+
+	m := Accumulate(CancelNeverFirstError())
+	WorkFor(context.Background(), nil, m, 10, func(ctx context.Context, index int) error {
+		if index%3 == 0 {
+			return &workerError{index: index, err: failed}
+		}
+		return nil
+	})
+
+	gerr := m.GroupError()
+	if gerr == nil || gerr.Len() != 4 {
+		t.Fatalf("Expecting group error with 4 errors: %v", gerr)
+	}
+	if indices := gerr.Indices(); indices[0] != 0 || indices[3] != 9 {
+		t.Fatalf("Expecting indices in ascending order: %v", indices)
+	}
+	if gerr.ByIndex(6) == nil || gerr.ByIndex(7) != nil {
+		t.Fatalf("Expecting errors by index")
+	}
+	if !errors.Is(gerr, failed) || len(gerr.Unwrap()) != 4 {
+		t.Fatalf("Expecting group error to unwrap to worker errors")
+	}
+	var werr *workerError
+	if !gerr.Is(failed) || gerr.Is(context.Canceled) || !gerr.As(&werr) || werr.index != 0 {
+		t.Fatalf("Expecting group error to match worker errors without Unwrap")
+	}
+	if msg := gerr.Error(); !strings.HasPrefix(msg, "4 errors: worker 0: failed; worker 3:") {
+		t.Fatalf("Unexpected group error message: %s", msg)
+	}
+
+	if gerr := Accumulate(nil).GroupError(); gerr != nil {
+		t.Fatalf("Expecting no group error without errors")
+	}
+}
+
+type infoRecorder struct {
+	Manager
+	mutex sync.Mutex
+	infos map[int]WorkerInfo
+}
+
+func (m *infoRecorder) ManageInfo(ctx context.Context, c Canceller, info WorkerInfo, err *error) int {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	m.infos[info.Index] = info
+	m.mutex.Unlock()
+	return m.Manage(ctx, c, info.Index, err)
+}
+
+func TestInfoManager(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	m := &infoRecorder{Manager: CancelNeverFirstError(), infos: make(map[int]WorkerInfo)}
+	err := Work(WithGroupName(context.Background(), "info"), nil, Recover(m),
+		Named("sleep", func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}),
+		Retry(3, time.Millisecond, func(ctx context.Context) error {
+			return failed
+		}),
+		func(ctx context.Context) error {
+			panic("info")
+		},
+	)
+
+	var perr *PanicError
+	if !errors.As(err, &perr) && !errors.Is(err, failed) {
+		t.Fatalf("Unexpected work group error: %v", err)
+	}
+	if len(m.infos) != 3 {
+		t.Fatalf("Expecting metadata of 3 workers, got %d", len(m.infos))
+	}
+	if info := m.infos[0]; info.Name != "sleep" || info.Group != "info" || info.Elapsed < 5*time.Millisecond || info.Attempt != 1 {
+		t.Fatalf("Unexpected metadata of worker 0: %+v", info)
+	}
+	if info := m.infos[1]; info.Attempt != 3 {
+		t.Fatalf("Expecting 3 attempts of worker 1, got %d", info.Attempt)
+	}
+}
+
+func TestNeverCancels(t *testing.T) {
+
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shared := func(ctx context.Context, m Manager) bool {
+		var same bool
+		WorkFor(ctx, nil, m, 1, func(ctx context.Context, index int) error {
+			same = ctx.Done() == parent.Done()
+			return nil
+		})
+		return same
+	}
+
+	sctx := WithSharedContext(parent)
+	if !shared(sctx, Recover(Accumulate(CancelNeverFirstError()))) {
+		t.Fatalf("Expecting no cancellable context for a manager that never cancels")
+	}
+	if shared(sctx, CancelNeverFirstError(WithClassifier(classifyTest))) {
+		t.Fatalf("Expecting cancellable context for a manager with a classifier")
+	}
+	if shared(sctx, CancelOnFirstError()) {
+		t.Fatalf("Expecting cancellable context for a manager that cancels")
+	}
+
+	// by default the context of the workers is cancelled when the group completes
+	var wctx context.Context
+	WorkFor(parent, nil, CancelNeverFirstError(), 1, func(ctx context.Context, index int) error {
+		wctx = ctx
+		return nil
+	})
+	if wctx.Err() != context.Canceled {
+		t.Fatalf("Expecting context of worker cancelled, got %v", wctx.Err())
+	}
+
+	// by default the running workers are cancelled by a shutdown
+	expired, expire := context.WithCancel(context.Background())
+	expire()
+	err := WorkFor(parent, nil, CancelNeverFirstError(), 1, func(ctx context.Context, index int) error {
+		CurrentGroup(ctx).Shutdown(expired)
+		return ctx.Err()
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expecting worker cancelled by shutdown, got %v", err)
+	}
+}
+
+func TestCollectPanics(t *testing.T) {
+
+	err := WorkFor(context.Background(), nil, Recover(CancelNeverFirstError(), CollectPanics(3)), 10, func(ctx context.Context, index int) error {
+		if index%2 == 0 {
+			panic(fmt.Sprintf("worker %d panicked", index))
+		}
+		return nil
+	})
 
-	for len(m.Errors) < n {
-		m.Errors = append(m.Errors, nil)
+	var perrs *PanicErrors
+	if !errors.As(err, &perrs) {
+		t.Fatalf("Expecting PanicErrors, got %v", err)
 	}
-	m.Errors[n-1] = *err
+	if len(perrs.Panics) != 3 || perrs.Dropped != 2 {
+		t.Fatalf("Expecting 3 panics kept and 2 dropped, got %d and %d", len(perrs.Panics), perrs.Dropped)
+	}
+	for _, p := range perrs.Panics {
+		var perr *PanicError
+		if !errors.As(p, &perr) || perr.Value() != fmt.Sprintf("worker %d panicked", p.Index) {
+			t.Fatalf("Expecting panic of worker %d: %v", p.Index, p)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "5 panics: worker ") {
+		t.Fatalf("Unexpected message: %s", err.Error())
+	}
+
+	var perr, first *PanicError
+	errors.As(perrs.Panics[0], &first)
+	if !perrs.As(&perr) || perr != first || !perrs.Is(perrs.Panics[1]) {
+		t.Fatalf("Expecting panics matched without Unwrap")
+	}
+}
+
+func TestRecoverContinue(t *testing.T) {
+
+	var processed int32
+	err := WorkFor(context.Background(), nil, RecoverContinue(CancelOnFirstError()), 100, func(ctx context.Context, index int) error {
+		if index == 3 {
+			panic("malformed input")
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+
+	if processed != 99 {
+		t.Fatalf("Expecting 99 workers processed, got %d", processed)
+	}
+	var werr *WorkerError
+	if !errors.As(err, &werr) || werr.Index != 3 {
+		t.Fatalf("Expecting WorkerError of worker 3, got %v", err)
+	}
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value() != "malformed input" {
+		t.Fatalf("Expecting PanicError, got %v", err)
+	}
+
+	err = WorkFor(context.Background(), nil, RecoverContinue(CancelOnFirstError()), 10, func(ctx context.Context, index int) error {
+		if index == 3 {
+			return errors.New("failed")
+		}
+		return ctx.Err()
+	})
+	if err == nil || err.Error() != "failed" {
+		t.Fatalf("Expecting an error to still cancel, got %v", err)
+	}
+}
+
+func TestCancelOnFirstErrorOnce(t *testing.T) {
+
+	var cancels int
+	c := CancellerFunc(func() { cancels++ })
+
+	m := RecoverContinue(CancelOnFirstError())
+	for i := 0; i < 3; i++ {
+		err := errors.New("failed")
+		m.Manage(context.Background(), c, i, &err)
+	}
+	if cancels != 1 {
+		t.Fatalf("Expecting the work group cancelled once, got %d", cancels)
+	}
+
+	// a panic that does not cancel does not count
+	cancels = 0
+	m = RecoverContinue(CancelOnFirstError())
+	func() {
+		defer m.Manage(context.Background(), c, 0, new(error))
+		panic("malformed input")
+	}()
+	err := errors.New("failed")
+	m.Manage(context.Background(), c, 1, &err)
+	if cancels != 1 {
+		t.Fatalf("Expecting the work group cancelled after a panic, got %d", cancels)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+
+	e := NewLimited(1)
+	SetDefaultExecuter(e)
+	SetDefaultManager(func() Manager { return CancelNeverFirstError() })
+	defer SetDefaultExecuter(nil)
+	defer SetDefaultManager(nil)
+
+	if DefaultExecuter() != e {
+		t.Fatalf("Expecting default executer to be configured")
+	}
+
+	var completed int32
+	err := Work(context.Background(), nil, nil,
+		func(ctx context.Context) error {
+			return errors.New("failed")
+		},
+		func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			atomic.AddInt32(&completed, 1)
+			return nil
+		},
+	)
+	if err == nil || completed != 1 {
+		t.Fatalf("Expecting default manager not to cancel, got %d: %v", completed, err)
+	}
+}
+
+// benchmarkManage calls the Manage method of the manager
+// concurrently, as the workers of a large work group do.
+func benchmarkManage(b *testing.B, m Manager) {
+	ctx := context.Background()
+	c := CancellerFunc(func() {})
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var err error
+			m.Manage(ctx, c, 0, &err)
+		}
+	})
+}
+
+func BenchmarkManageCancelOnFirstError(b *testing.B) {
+	benchmarkManage(b, CancelOnFirstError())
+}
+
+func BenchmarkManageCancelOnFirstSuccess(b *testing.B) {
+	benchmarkManage(b, CancelOnFirstSuccess())
+}
+
+func BenchmarkManageCancelOnFirstComplete(b *testing.B) {
+	benchmarkManage(b, CancelOnFirstComplete())
+}
+
+func BenchmarkManageCancelNeverFirstError(b *testing.B) {
+	benchmarkManage(b, CancelNeverFirstError())
+}
+
+func benchmarkAccumulate(b *testing.B, opts ...ManagerOption) {
+	m := Accumulate(CancelNeverFirstError(), opts...)
+	ctx := context.Background()
+	c := CancellerFunc(func() {})
+	failed := errors.New("failed")
+	var next int64
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := failed
+			m.Manage(ctx, c, int(atomic.AddInt64(&next, 1)), &err)
+		}
+	})
+}
+
+func BenchmarkAccumulate(b *testing.B) {
+	benchmarkAccumulate(b)
+}
 
-	return n
+func BenchmarkAccumulateShards(b *testing.B) {
+	benchmarkAccumulate(b, WithShards(0))
 }
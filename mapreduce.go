@@ -0,0 +1,58 @@
+package workgroup
+
+import "context"
+
+type treeKey struct{}
+
+// TreeReduce returns a copy of the context, ctx, that configures
+// MapReduce to reduce the results in parallel, by reducing adjacent
+// pairs of results with the executer in rounds, rather than reducing
+// them in order on the calling goroutine. The reduce function must be
+// associative and safe to call concurrently.
+func TreeReduce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, treeKey{}, true)
+}
+
+// MapReduce arranges for the function, mapFn, to be executed for each of
+// the items, in parallel with the executer, e, and then reduces the results
+// in order of the items with the function, reduceFn, see TreeReduce. The
+// work group is cancelled when the map function of an item returns an
+// error, and the error is returned. If there are no items then the zero
+// value of R is returned. See documention for Work() for details.
+func MapReduce[T, R any](ctx context.Context, e Executer, items []T, mapFn func(context.Context, T) (R, error), reduceFn func(R, R) R) (R, error) {
+	var result R
+	results := make([]R, len(items))
+	err := WorkFor(ctx, e, CancelOnFirstError(), len(items), func(ctx context.Context, i int) error {
+		r, err := mapFn(ctx, items[i])
+		results[i] = r
+		return err
+	})
+	if err != nil || len(results) == 0 {
+		return result, err
+	}
+
+	if ctx != nil && ctx.Value(treeKey{}) != nil {
+		for len(results) > 1 {
+			next := make([]R, (len(results)+1)/2)
+			err := WorkFor(ctx, e, CancelOnFirstError(), len(next), func(ctx context.Context, i int) error {
+				if 2*i+1 < len(results) {
+					next[i] = reduceFn(results[2*i], results[2*i+1])
+				} else {
+					next[i] = results[2*i]
+				}
+				return nil
+			})
+			if err != nil {
+				return result, err
+			}
+			results = next
+		}
+		return results[0], nil
+	}
+
+	result = results[0]
+	for _, r := range results[1:] {
+		result = reduceFn(result, r)
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestMapReduce(t *testing.T) {
+
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	digit := func(ctx context.Context, i int) (string, error) {
+		return strconv.Itoa(i%10) + ",", nil
+	}
+	concat := func(a, b string) string {
+		return a + b
+	}
+
+	var want string
+	for _, i := range items {
+		want += strconv.Itoa(i%10) + ","
+	}
+
+	for _, ctx := range []context.Context{context.Background(), TreeReduce(context.Background())} {
+		got, err := MapReduce(ctx, NewLimited(4), items, digit, concat)
+		if err != nil || got != want {
+			t.Fatalf("Expecting %q, got %q: %v", want, got, err)
+		}
+	}
+
+	failed := errors.New("failed")
+	_, err := MapReduce(context.Background(), nil, items, func(ctx context.Context, i int) (int, error) {
+		if i == 50 {
+			return 0, failed
+		}
+		return i, nil
+	}, func(a, b int) int { return a + b })
+	if err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+}
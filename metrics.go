@@ -0,0 +1,223 @@
+package workgroup
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSink records the metrics of instrumented work groups, see
+// Instrument. The methods may be called concurrently from multiple
+// goroutines.
+type MetricsSink interface {
+	// QueueDepth records the number of workers that
+	// have been submitted but have not yet started.
+	QueueDepth(n int)
+
+	// Started records that a worker has started.
+	Started()
+
+	// Finished records that a worker has completed
+	// after the duration, d, with the error, err.
+	Finished(d time.Duration, err error)
+}
+
+type instrumentKey struct{}
+
+type instrumentExecuter struct {
+	e      Executer
+	sink   MetricsSink
+	queued int64
+}
+
+type instrumentManager struct {
+	m    Manager
+	sink MetricsSink
+}
+
+// Instrument wraps an Executer, e, and a Manager, m, to record the
+// metrics of the workers of the work groups that use them to the sink.
+// The executer records the queue depth and the start of each worker,
+// and the manager records the completion of each worker, with its
+// duration and error. The duration is measured from the start of the
+// worker when the instrumented executer is also used. If executer, e,
+// or manager, m, are not provided then the defaults are used.
+func Instrument(e Executer, m Manager, sink MetricsSink) (Executer, Manager) {
+	if e == nil {
+		e = DefaultExecuter()
+	}
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &instrumentExecuter{e: e, sink: sink}, &instrumentManager{m: m, sink: sink}
+}
+
+func (i *instrumentExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	i.sink.QueueDepth(int(atomic.AddInt64(&i.queued, 1)))
+	i.e.Execute(ctx, func(ctx context.Context) {
+		i.sink.QueueDepth(int(atomic.AddInt64(&i.queued, -1)))
+		i.sink.Started()
+		f(context.WithValue(ctx, instrumentKey{}, ClockFrom(ctx).Now()))
+	})
+}
+
+func (i *instrumentManager) Error() error {
+	return i.m.Error()
+}
+
+func (i *instrumentManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := i.m.Manage(ctx, c, idx, err)
+
+	var d time.Duration
+	if start, ok := ctx.Value(instrumentKey{}).(time.Time); ok {
+		d = ClockFrom(ctx).Now().Sub(start)
+	}
+	i.sink.Finished(d, *err)
+	return n
+}
+
+// Counter is a metric that counts events, it is
+// implemented by prometheus.Counter, for example.
+type Counter interface {
+	Inc()
+}
+
+// Gauge is a metric that records a value, it is
+// implemented by prometheus.Gauge, for example.
+type Gauge interface {
+	Set(float64)
+}
+
+// Observer is a metric that records observations, it is
+// implemented by prometheus.Histogram, for example.
+type Observer interface {
+	Observe(float64)
+}
+
+// PrometheusMetrics contains the metrics of a Prometheus sink, the
+// metrics are created and registered by the caller, so that this package
+// does not depend on the Prometheus client. Any of the metrics may be nil.
+type PrometheusMetrics struct {
+	// Started counts the workers that have started.
+	Started Counter
+
+	// Finished counts the workers that have completed.
+	Finished Counter
+
+	// Errors counts the workers that have completed with an error.
+	Errors Counter
+
+	// Duration observes the duration of the workers in seconds.
+	Duration Observer
+
+	// QueueDepth records the number of workers waiting to start.
+	QueueDepth Gauge
+}
+
+type prometheusSink struct {
+	m PrometheusMetrics
+}
+
+// NewPrometheusSink returns a sink that records to the Prometheus metrics.
+func NewPrometheusSink(m PrometheusMetrics) MetricsSink {
+	return &prometheusSink{m: m}
+}
+
+func (s *prometheusSink) QueueDepth(n int) {
+	if s.m.QueueDepth != nil {
+		s.m.QueueDepth.Set(float64(n))
+	}
+}
+
+func (s *prometheusSink) Started() {
+	if s.m.Started != nil {
+		s.m.Started.Inc()
+	}
+}
+
+func (s *prometheusSink) Finished(d time.Duration, err error) {
+	if s.m.Finished != nil {
+		s.m.Finished.Inc()
+	}
+	if err != nil && s.m.Errors != nil {
+		s.m.Errors.Inc()
+	}
+	if s.m.Duration != nil {
+		s.m.Duration.Observe(d.Seconds())
+	}
+}
+
+type expvarSink struct {
+	queueDepth *expvar.Int
+	started    *expvar.Int
+	finished   *expvar.Int
+	errors     *expvar.Int
+	duration   *expvar.Float
+}
+
+// NewExpvarSink returns a sink that records to an expvar.Map published
+// with the given name, the map contains the variables "queue_depth",
+// "started", "finished", "errors" and "duration_seconds", which is the
+// total duration of the workers. If a map is already published with the
+// name, for example, by another sink, then the sink records to the
+// variables of that map. Like expvar.NewMap, it panics if the name is
+// already published with a variable that is not a map.
+func NewExpvarSink(name string) MetricsSink {
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+
+	m, _ := expvar.Get(name).(*expvar.Map)
+	if m == nil {
+		m = expvar.NewMap(name)
+	}
+	return &expvarSink{
+		queueDepth: expvarInt(m, "queue_depth"),
+		started:    expvarInt(m, "started"),
+		finished:   expvarInt(m, "finished"),
+		errors:     expvarInt(m, "errors"),
+		duration:   expvarFloat(m, "duration_seconds"),
+	}
+}
+
+// expvarMutex serializes the publication of the maps of expvar sinks.
+var expvarMutex sync.Mutex
+
+// expvarInt returns the integer variable of the map, m, with the
+// given key, which is added to the map if it is not present.
+func expvarInt(m *expvar.Map, key string) *expvar.Int {
+	if v, ok := m.Get(key).(*expvar.Int); ok {
+		return v
+	}
+	v := new(expvar.Int)
+	m.Set(key, v)
+	return v
+}
+
+// expvarFloat returns the float variable of the map, m, with the
+// given key, which is added to the map if it is not present.
+func expvarFloat(m *expvar.Map, key string) *expvar.Float {
+	if v, ok := m.Get(key).(*expvar.Float); ok {
+		return v
+	}
+	v := new(expvar.Float)
+	m.Set(key, v)
+	return v
+}
+
+func (s *expvarSink) QueueDepth(n int) {
+	s.queueDepth.Set(int64(n))
+}
+
+func (s *expvarSink) Started() {
+	s.started.Add(1)
+}
+
+func (s *expvarSink) Finished(d time.Duration, err error) {
+	s.finished.Add(1)
+	if err != nil {
+		s.errors.Add(1)
+	}
+	s.duration.Add(d.Seconds())
+}
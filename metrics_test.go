@@ -0,0 +1,65 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCounter struct {
+	n int64
+}
+
+func (c *testCounter) Inc() {
+	atomic.AddInt64(&c.n, 1)
+}
+
+func TestInstrumentPrometheus(t *testing.T) {
+
+	var started, finished, errs testCounter
+	sink := NewPrometheusSink(PrometheusMetrics{
+		Started:  &started,
+		Finished: &finished,
+		Errors:   &errs,
+	})
+
+	e, m := Instrument(NewLimited(2), CancelNeverFirstError(), sink)
+	WorkFor(context.Background(), e, m, 10, func(ctx context.Context, index int) error {
+		if index%5 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if started.n != 10 || finished.n != 10 || errs.n != 2 {
+		t.Fatalf("Unexpected metrics: started %d, finished %d, errors %d", started.n, finished.n, errs.n)
+	}
+}
+
+func TestInstrumentExpvar(t *testing.T) {
+
+	// the map is reused by sinks with the same name
+	NewExpvarSink("workgroup_test")
+	vars := expvar.Get("workgroup_test").(*expvar.Map)
+	finished := vars.Get("finished").(*expvar.Int).Value()
+	duration := vars.Get("duration_seconds").(*expvar.Float).Value()
+
+	e, m := Instrument(nil, nil, NewExpvarSink("workgroup_test"))
+	WorkFor(context.Background(), e, m, 3, func(ctx context.Context, index int) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	if n := vars.Get("finished").(*expvar.Int).Value() - finished; n != 3 {
+		t.Fatalf("Expecting 3 finished workers, got %d", n)
+	}
+	if v := vars.Get("queue_depth").String(); v != "0" {
+		t.Fatalf("Expecting queue depth of 0, got %s", v)
+	}
+	if d := vars.Get("duration_seconds").(*expvar.Float).Value() - duration; d < 0.003 {
+		t.Fatalf("Expecting total duration of at least 3ms, got %f", d)
+	}
+}
@@ -0,0 +1,110 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type groupNameKey struct{}
+
+// WithGroupName returns a copy of the context, ctx, that names the work
+// group started with it, for diagnostics. The errors of the workers of a
+// named group are annotated as a WorkerError with the name of the group,
+// and the name is included in the WorkerInfo reported by the watchdog and
+// by Dump. The error of a named group is a FailedGroupError with the name
+// and the number of workers that failed. Work groups nested in the group
+// are not named.
+func WithGroupName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, groupNameKey{}, name)
+}
+
+// Named returns a worker that names the worker, w, for diagnostics.
+// When executed by a work group, the error of the worker, including a
+// panic recovered by the manager, is annotated as a WorkerError with
+// the name and index of the worker, and the name is included in the
+// WorkerInfo reported by the watchdog and by Dump.
+func Named(name string, w Worker) Worker {
+	return func(ctx context.Context) error {
+		if g, ok := ctx.Value(groupKey{}).(*group); ok {
+			if index, ok := IndexFromContext(ctx); ok {
+				g.setName(index, name)
+			}
+		}
+		return w(ctx)
+	}
+}
+
+// WorkerName returns the name of the worker with the
+// context, ctx, or an empty string if it is not named.
+func WorkerName(ctx context.Context) string {
+	g, ok := ctx.Value(groupKey{}).(*group)
+	if !ok {
+		return ""
+	}
+	index, _ := IndexFromContext(ctx)
+	return g.workerName(index)
+}
+
+// Name returns the name of the work group, see WithGroupName.
+func (s *Scope) Name() string {
+	return s.g.name
+}
+
+// setName records the name of the worker with the given index.
+func (g *group) setName(index int, name string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.names == nil {
+		g.names = make(map[int]string)
+		atomic.StoreInt32(&g.named, 1)
+	}
+	g.names[index] = name
+}
+
+// workerName returns the name of the worker with the given index.
+func (g *group) workerName(index int) string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.names[index]
+}
+
+// forget removes the name of the worker with the given index.
+func (g *group) forget(index int) {
+	if atomic.LoadInt32(&g.named) == 0 {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.names, index)
+}
+
+// annotate annotates the error, err, of the worker with the given
+// index as a WorkerError, if the worker or the group is named.
+func (g *group) annotate(index int, err error) error {
+	if err == nil {
+		return nil
+	}
+	name := g.workerName(index)
+	if name == "" && g.name == "" {
+		return err
+	}
+	if we, ok := err.(*WorkerError); ok && we.Index == index {
+		c := *we
+		if c.Name == "" {
+			c.Name = name
+		}
+		if c.Group == "" {
+			c.Group = g.name
+		}
+		return &c
+	}
+	return &WorkerError{Index: index, Name: name, Group: g.name, Err: err}
+}
+
+// describe fills the names of the worker and group in the info, if any.
+func describe(ctx context.Context, info *WorkerInfo) {
+	if g, ok := ctx.Value(groupKey{}).(*group); ok {
+		info.Name = g.workerName(info.Index)
+		info.Group = g.name
+	}
+}
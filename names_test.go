@@ -0,0 +1,68 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNamedWorkers(t *testing.T) {
+
+	failed := errors.New("failed")
+	ctx := WithGroupName(context.Background(), "backfill")
+
+	err := Work(ctx, NewSerial(), nil,
+		Named("fetch-users", func(ctx context.Context) error {
+			if name := WorkerName(ctx); name != "fetch-users" {
+				t.Errorf("Expecting worker name 'fetch-users', got %q", name)
+			}
+			if name := CurrentGroup(ctx).Name(); name != "backfill" {
+				t.Errorf("Expecting group name 'backfill', got %q", name)
+			}
+			return nil
+		}),
+		Named("fetch-orders", func(ctx context.Context) error {
+			return failed
+		}),
+	)
+	if !errors.Is(err, failed) {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+	if msg := err.Error(); msg != `workgroup "backfill": 1/2 workers failed: worker fetch-orders[1]: failed` {
+		t.Fatalf("Unexpected error message: %s", msg)
+	}
+	var gerr *FailedGroupError
+	if !errors.As(err, &gerr) || gerr.Failed != 1 || gerr.Total != 2 {
+		t.Fatalf("Expecting FailedGroupError of 1 of 2 workers, got %v", err)
+	}
+	var werr *WorkerError
+	if !errors.As(err, &werr) || werr.Group != "backfill" || werr.Index != 1 {
+		t.Fatalf("Expecting WorkerError of worker 1, got %v", err)
+	}
+
+	err = Work(context.Background(), nil, Recover(CancelOnFirstError()), Named("parse", func(ctx context.Context) error {
+		panic("bad input")
+	}))
+	var perr *PanicError
+	if !errors.As(err, &perr) || err.Error() != "worker parse[0]: panic: bad input" {
+		t.Fatalf("Expecting named panic error, got %v", err)
+	}
+}
+
+func TestNamedWatchdog(t *testing.T) {
+
+	stalled := make(chan WorkerInfo, 1)
+	ctx := WithWatchdog(WithGroupName(context.Background(), "backfill"), 5*time.Millisecond, func(info WorkerInfo) {
+		stalled <- info
+	})
+	Work(ctx, nil, nil, Named("slow", func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}))
+
+	info := <-stalled
+	if info.Name != "slow" || info.Group != "backfill" {
+		t.Fatalf("Expecting names in watchdog report, got %+v", info)
+	}
+}
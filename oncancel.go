@@ -0,0 +1,63 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// OnCancel registers the function, fn, to be called once, on a new
+// goroutine, when the context of the work group is cancelled before the
+// group completes, see Handle.OnCancel. The cause is the error of the
+// context that the group was started with, if it was cancelled, or
+// context.Canceled if the group was shut down, or otherwise the error
+// of the manager that cancelled the group. The function is not called
+// if the group completes without being cancelled.
+func (s *Scope) OnCancel(fn func(cause error)) {
+	s.g.onCancel(fn)
+}
+
+// OnCancel registers the function, fn, to be called once when the context
+// of the work group is cancelled, for example, to close a producer or to
+// release a lease, see Scope.OnCancel.
+func (h *Handle) OnCancel(fn func(cause error)) {
+	h.grp.onCancel(fn)
+}
+
+func (g *group) onCancel(fn func(cause error)) {
+	// the group is not reused once a function is registered, see Runner
+	atomic.StoreInt32(&g.listeners, 1)
+	cancelled, done := g.ctx.Done(), g.done
+	go func() {
+		select {
+		case <-cancelled:
+		case <-done:
+			return
+		}
+		select {
+		case <-done:
+			// cancelled by the completion of the group
+			return
+		default:
+		}
+		fn(g.cause())
+	}()
+}
+
+// cause returns the cause of the cancellation of the group.
+func (g *group) cause() error {
+	if err := g.base.Err(); err != nil {
+		return interrupted(g.base, err)
+	}
+	if g.stopped() {
+		return context.Canceled
+	}
+	m := g.m
+	if w, ok := m.(*recoverWrapper); ok {
+		// Repanic panics with the error of the manager
+		m = w.m
+	}
+	if err := m.Error(); err != nil {
+		return err
+	}
+	return context.Canceled
+}
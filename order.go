@@ -0,0 +1,116 @@
+package workgroup
+
+import "context"
+
+type orderedKey struct{}
+
+// OrderedResults returns a copy of the context, ctx, that configures
+// WorkStream and WorkChanCollect, when started with it, to send results
+// in the order of the workers that produced them, rather than in the
+// order that the workers complete. Results that complete out of order
+// are buffered until the results of the preceding workers have been
+// sent. The result of a worker that is not invoked, see SkipIfCancelled,
+// is not known until the group completes, so following results are
+// buffered until then.
+func OrderedResults(ctx context.Context) context.Context {
+	return context.WithValue(ctx, orderedKey{}, true)
+}
+
+// sequenced is a result with the index of the worker that produced it,
+// the result is not sent if ok is false.
+type sequenced[T any] struct {
+	index int
+	value T
+	ok    bool
+}
+
+// emitter sends the results of workers on a channel, in the order of
+// the workers if configured by OrderedResults, from the first index.
+type emitter[T any] struct {
+	ctx   context.Context
+	out   chan<- T
+	first int
+	in    chan sequenced[T]
+	done  chan struct{}
+}
+
+func newEmitter[T any](ctx context.Context, out chan<- T, first int) *emitter[T] {
+	e := &emitter[T]{ctx: ctx, out: out, first: first}
+	if ctx.Value(orderedKey{}) != nil {
+		e.in = make(chan sequenced[T])
+		e.done = make(chan struct{})
+		go e.reorder()
+	}
+	return e
+}
+
+// emit sends the value, v, of the worker with the given index, unless
+// the context is cancelled. If ok is false, then no value is sent.
+func (e *emitter[T]) emit(index int, v T, ok bool) {
+	if e.in == nil {
+		if ok {
+			select {
+			case e.out <- v:
+			case <-e.ctx.Done():
+			}
+		}
+		return
+	}
+	select {
+	case e.in <- sequenced[T]{index: index, value: v, ok: ok}:
+	case <-e.ctx.Done():
+	}
+}
+
+// close waits for the buffered results to be sent, the output
+// channel is not closed. All of the workers must have completed.
+func (e *emitter[T]) close() {
+	if e.in != nil {
+		close(e.in)
+		<-e.done
+	}
+}
+
+// reorder sends the results received from the workers in order of index.
+func (e *emitter[T]) reorder() {
+	defer close(e.done)
+
+	in := e.in
+	next := e.first
+	pending := make(map[int]sequenced[T])
+	for in != nil || len(pending) > 0 {
+		var out chan<- T
+		s, found := pending[next]
+		switch {
+		case found && !s.ok:
+			delete(pending, next)
+			next++
+			continue
+		case found:
+			out = e.out
+		case in == nil:
+			// skip the results of workers that were not invoked
+			next = -1
+			for index := range pending {
+				if next < 0 || index < next {
+					next = index
+				}
+			}
+			continue
+		}
+
+		select {
+		case r, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			pending[r.index] = r
+		case out <- s.value:
+			delete(pending, next)
+			next++
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+type outputKey struct{}
+
+type writerKey struct{}
+
+type outputConfig struct {
+	w     io.Writer
+	order OutputOrder
+}
+
+// OutputOrder specifies the order that the output
+// of the workers of a group is written.
+type OutputOrder int
+
+const (
+	// Interleaved writes each line of output
+	// as soon as the line is complete.
+	Interleaved OutputOrder = iota
+
+	// CompletionOrder buffers the output of each worker
+	// and writes it when the worker completes.
+	CompletionOrder
+
+	// SubmissionOrder buffers the output of each worker and
+	// writes it in the order that the workers were submitted,
+	// so the output reads as if the workers ran sequentially.
+	SubmissionOrder
+)
+
+// OutputOption configures optional behavior of the output multiplexer.
+type OutputOption func(*outputConfig)
+
+// WithOutputOrder configures the order that the output
+// of the workers is written, the default is Interleaved.
+func WithOutputOrder(order OutputOrder) OutputOption {
+	return func(cfg *outputConfig) {
+		cfg.order = order
+	}
+}
+
+// WithOutput returns a copy of the context, ctx, that configures
+// work groups started with it to multiplex the output of their
+// workers to the writer, w. Workers obtain their writer by calling
+// WriterFrom. The output is written a line at a time and each line
+// is prefixed with the index of the worker. The output of a nested
+// work group is written to the writer of its parent worker, so the
+// lines are prefixed with the index of each enclosing worker.
+func WithOutput(ctx context.Context, w io.Writer, opts ...OutputOption) context.Context {
+	cfg := &outputConfig{w: w}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return context.WithValue(ctx, outputKey{}, cfg)
+}
+
+// WriterFrom returns the writer for the worker with the context,
+// ctx. If the work group was not started with a context configured
+// by WithOutput then the result is os.Stdout.
+func WriterFrom(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(writerKey{}).(*workerWriter); ok {
+		return w
+	}
+	return os.Stdout
+}
+
+// output multiplexes the output of the workers of a group.
+type output struct {
+	mutex sync.Mutex
+	w     io.Writer
+	order OutputOrder
+	queue []int
+	done  map[int][]byte
+}
+
+func newOutput(ctx context.Context) *output {
+	cfg, ok := ctx.Value(outputKey{}).(*outputConfig)
+	if !ok {
+		return nil
+	}
+
+	w := cfg.w
+	if pw, ok := ctx.Value(writerKey{}).(*workerWriter); ok {
+		w = pw
+	}
+	return &output{
+		w:     w,
+		order: cfg.order,
+		done:  make(map[int][]byte),
+	}
+}
+
+// writer returns the writer of the worker with the given index, which
+// is called when the worker is submitted, so that in submission order
+// the output of the workers that are never submitted, for example, as
+// they were skipped by a checkpoint, is not waited for.
+func (o *output) writer(index int) *workerWriter {
+	if o.order == SubmissionOrder {
+		o.mutex.Lock()
+		o.queue = append(o.queue, index)
+		o.mutex.Unlock()
+	}
+	return &workerWriter{
+		out:    o,
+		index:  index,
+		prefix: []byte("[" + strconv.Itoa(index) + "] "),
+	}
+}
+
+func (o *output) write(p []byte) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	_, err := o.w.Write(p)
+	return err
+}
+
+// complete writes the buffered output, p, of the worker with the
+// given index, in submission order the output is held until the
+// output of all the preceding workers has been written.
+func (o *output) complete(index int, p []byte) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.order != SubmissionOrder {
+		if len(p) > 0 {
+			o.w.Write(p)
+		}
+		return
+	}
+
+	o.done[index] = p
+	for len(o.queue) > 0 {
+		p, ok := o.done[o.queue[0]]
+		if !ok {
+			return
+		}
+		if len(p) > 0 {
+			o.w.Write(p)
+		}
+		delete(o.done, o.queue[0])
+		o.queue = o.queue[1:]
+	}
+}
+
+// workerWriter buffers the output of a single worker
+// and writes complete lines to the group output.
+type workerWriter struct {
+	mutex  sync.Mutex
+	out    *output
+	index  int
+	prefix []byte
+	buf    []byte
+	lbuf   []byte
+}
+
+func (w *workerWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+	n := bytes.LastIndexByte(w.buf, '\n') + 1
+	if n == 0 {
+		return len(p), nil
+	}
+
+	lines := w.lines(w.buf[:n])
+	w.buf = append(w.buf[:0], w.buf[n:]...)
+	if w.out.order != Interleaved {
+		w.lbuf = append(w.lbuf, lines...)
+		return len(p), nil
+	}
+	if err := w.out.write(lines); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// lines returns the complete lines, p, with the prefix added to each line.
+func (w *workerWriter) lines(p []byte) []byte {
+	var b bytes.Buffer
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n') + 1
+		b.Write(w.prefix)
+		b.Write(p[:i])
+		p = p[i:]
+	}
+	return b.Bytes()
+}
+
+// flush writes any output remaining in the buffers
+// and is called when the worker completes.
+func (w *workerWriter) flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.buf) > 0 {
+		w.buf = append(w.buf, '\n')
+		w.lbuf = append(w.lbuf, w.lines(w.buf)...)
+		w.buf = w.buf[:0]
+	}
+
+	if w.out.order == Interleaved {
+		if len(w.lbuf) > 0 {
+			w.out.write(w.lbuf)
+		}
+	} else {
+		w.out.complete(w.index, w.lbuf)
+	}
+	w.lbuf = nil
+}
@@ -0,0 +1,156 @@
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkOutput(t *testing.T) {
+
+	var buf bytes.Buffer
+	ctx := WithOutput(context.Background(), &buf)
+
+	WorkFor(ctx, nil, nil, 100, func(ctx context.Context, index int) error {
+		w := WriterFrom(ctx)
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(w, "line %d ", i)
+			fmt.Fprintf(w, "of worker %d\n", index)
+		}
+		fmt.Fprintf(w, "done")
+		return nil
+	})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 1100 {
+		t.Fatalf("Expecting 1100 lines of output, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var index, i, worker int
+		if _, err := fmt.Sscanf(line, "[%d] line %d of worker %d", &index, &i, &worker); err == nil {
+			if index != worker {
+				t.Fatalf("Output line has incorrect prefix: %s", line)
+			}
+		} else if _, err := fmt.Sscanf(line, "[%d] done", &index); err != nil {
+			t.Fatalf("Output line is interleaved: %s", line)
+		}
+	}
+}
+
+func TestNestedWorkOutput(t *testing.T) {
+
+	var buf bytes.Buffer
+	ctx := WithOutput(context.Background(), &buf)
+
+	Work(ctx, nil, nil,
+		func(ctx context.Context) error {
+			fmt.Fprintln(WriterFrom(ctx), "parent")
+			return nil
+		},
+		GroupFor(nil, nil, 2, func(ctx context.Context, index int) error {
+			fmt.Fprintln(WriterFrom(ctx), "child")
+			return nil
+		}),
+	)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	sort.Strings(lines)
+	expected := []string{"[0] parent", "[1] [0] child", "[1] [1] child"}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected nested output:\n%s", buf.String())
+	}
+}
+
+func TestWorkOutputSubmissionOrder(t *testing.T) {
+
+	var buf bytes.Buffer
+	ctx := WithOutput(context.Background(), &buf, WithOutputOrder(SubmissionOrder))
+
+	WorkFor(ctx, nil, nil, 100, func(ctx context.Context, index int) error {
+		w := WriterFrom(ctx)
+		for i := 0; i < 3; i++ {
+			time.Sleep(time.Duration(100-index) * time.Microsecond)
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		return nil
+	})
+
+	var expected strings.Builder
+	for index := 0; index < 100; index++ {
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(&expected, "[%d] line %d\n", index, i)
+		}
+	}
+	if buf.String() != expected.String() {
+		t.Fatalf("Output is not in submission order:\n%s", buf.String())
+	}
+}
+
+func TestWorkChanOutputSubmissionOrder(t *testing.T) {
+
+	var buf bytes.Buffer
+	ctx := WithOutput(context.Background(), &buf, WithOutputOrder(SubmissionOrder))
+
+	workers := make(chan Worker, 3)
+	for i := 0; i < 3; i++ {
+		delay := time.Duration(3-i) * 10 * time.Millisecond
+		workers <- func(ctx context.Context) error {
+			time.Sleep(delay)
+			fmt.Fprintf(WriterFrom(ctx), "line\n")
+			return nil
+		}
+	}
+	close(workers)
+	WorkChan(ctx, nil, nil, workers)
+
+	expected := "[1] line\n[2] line\n[3] line\n"
+	if buf.String() != expected {
+		t.Fatalf("Output is not in submission order:\n%s", buf.String())
+	}
+}
+
+type skipCheckpoint []int
+
+func (s skipCheckpoint) Save(ctx context.Context, index int) error { return nil }
+
+func (s skipCheckpoint) Load(ctx context.Context) ([]int, error) { return s, nil }
+
+func TestWorkOutputSubmissionOrderCheckpoint(t *testing.T) {
+
+	var buf bytes.Buffer
+	ctx := WithOutput(context.Background(), &buf, WithOutputOrder(SubmissionOrder))
+	ctx = WithCheckpoint(ctx, skipCheckpoint{1})
+
+	WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+		fmt.Fprintf(WriterFrom(ctx), "line\n")
+		return nil
+	})
+
+	expected := "[0] line\n[2] line\n"
+	if buf.String() != expected {
+		t.Fatalf("Expecting the output of the workers that were not skipped:\n%s", buf.String())
+	}
+}
+
+func TestWorkOutputCompletionOrder(t *testing.T) {
+
+	var buf bytes.Buffer
+	ctx := WithOutput(context.Background(), &buf, WithOutputOrder(CompletionOrder))
+
+	WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+		w := WriterFrom(ctx)
+		fmt.Fprintf(w, "first\n")
+		time.Sleep(time.Duration(3-index) * 10 * time.Millisecond)
+		fmt.Fprintf(w, "second")
+		return nil
+	})
+
+	expected := "[2] first\n[2] second\n[1] first\n[1] second\n[0] first\n[0] second\n"
+	if buf.String() != expected {
+		t.Fatalf("Output is not in completion order:\n%s", buf.String())
+	}
+}
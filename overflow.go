@@ -0,0 +1,85 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrQueueFull is the error of a worker that was rejected by an
+// executer because it could not be started or queued, see NewOverflow,
+// NewBoundedLimited and RejectWhenFull.
+var ErrQueueFull = errors.New("workgroup: queue full")
+
+// errDropped marks a worker that was dropped by an
+// executer, which is handled as if it succeeded.
+var errDropped = errors.New("workgroup: dropped")
+
+type rejectKey struct{}
+
+// rejected returns a cancelled copy of the context, ctx, that marks
+// the function called with it as rejected by the executer with the
+// error, err, which becomes the error of the worker.
+func rejected(ctx context.Context, err error) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	return context.WithValue(ctx, rejectKey{}, err)
+}
+
+// TryExecuter is implemented by executers that can execute a function
+// without blocking, such as the executers of NewLimited and NewPool.
+type TryExecuter interface {
+	Executer
+
+	// TryExecute executes the function, f, if it can be started without
+	// waiting for the limit of the executer, and reports whether it was.
+	TryExecute(ctx context.Context, f func(context.Context)) bool
+}
+
+// Overflow specifies what an executer does with a function
+// that cannot be started without waiting, see NewOverflow.
+type Overflow int
+
+const (
+	// OverflowBlock waits until the function can be started.
+	OverflowBlock Overflow = iota
+
+	// OverflowDrop drops the function, the work group of a worker
+	// that is dropped handles it as if it completed without error.
+	OverflowDrop
+
+	// OverflowError rejects the function, the work group of a worker
+	// that is rejected handles it as if it completed with the error,
+	// ErrQueueFull.
+	OverflowError
+)
+
+type overflow struct {
+	e      TryExecuter
+	policy Overflow
+}
+
+// NewOverflow returns an executer that executes functions with the
+// executer, e, and applies the policy to the functions that cannot be
+// started without waiting, so that producers, such as the channel of
+// WorkChan, need not block indefinitely when e is at its limit. The
+// function of a worker that is dropped or rejected is not invoked, it
+// is called on the calling goroutine with a cancelled context. If e is
+// not a TryExecuter, then every function waits as for OverflowBlock.
+func NewOverflow(e Executer, policy Overflow) Executer {
+	te, ok := e.(TryExecuter)
+	if !ok || policy == OverflowBlock {
+		return e
+	}
+	return &overflow{e: te, policy: policy}
+}
+
+func (o *overflow) Execute(ctx context.Context, f func(context.Context)) {
+	if o.e.TryExecute(ctx, f) {
+		return
+	}
+	if o.policy == OverflowDrop {
+		f(rejected(ctx, errDropped))
+		return
+	}
+	f(rejected(ctx, ErrQueueFull))
+}
@@ -0,0 +1,57 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOverflow(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executers := []func() Executer{
+		func() Executer {
+			return NewLimited(1)
+		},
+		func() Executer {
+			return NewPool(ctx, 1, WithPrewarm(0))
+		},
+	}
+	for _, e := range executers {
+		var ran int32
+		var release chan struct{}
+		worker := func(ctx context.Context, index int) error {
+			atomic.AddInt32(&ran, 1)
+			if index == 0 {
+				<-release
+			}
+			return nil
+		}
+		manager := func() Manager {
+			ran = 0
+			release = make(chan struct{})
+			return Observe(CancelNeverFirstError(), Hooks{OnComplete: func(idx int) {
+				if idx == 2 {
+					close(release)
+				}
+			}})
+		}
+
+		err := WorkFor(context.Background(), NewOverflow(e(), OverflowError), manager(), 3, worker)
+		if !errors.Is(err, ErrQueueFull) || ran != 1 {
+			t.Fatalf("Expecting workers rejected with %v, got %d run: %v", ErrQueueFull, ran, err)
+		}
+
+		err = WorkFor(context.Background(), NewOverflow(e(), OverflowDrop), manager(), 3, worker)
+		if err != nil || ran != 1 {
+			t.Fatalf("Expecting workers dropped, got %d run: %v", ran, err)
+		}
+	}
+
+	if e := NewUnlimited(); NewOverflow(e, OverflowError) != e {
+		t.Fatal("Expecting an executer that cannot try to execute to be returned")
+	}
+}
@@ -0,0 +1,57 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Pause pauses the work group, workers that have not started are not
+// submitted to the executer, or are not started by it, until the group
+// is resumed, while the running workers continue. This relieves the
+// pressure on a downstream system without losing the progress of the
+// group. A paused group that is cancelled or shut down continues.
+func (s *Scope) Pause() {
+	g := s.g
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.resume == nil {
+		g.resume = make(chan struct{})
+		atomic.StoreInt32(&g.paused, 1)
+	}
+}
+
+// Resume resumes the work group after it has been paused.
+func (s *Scope) Resume() {
+	g := s.g
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.resume != nil {
+		close(g.resume)
+		g.resume = nil
+		atomic.StoreInt32(&g.paused, 0)
+	}
+}
+
+// Paused reports whether the work group is paused.
+func (s *Scope) Paused() bool {
+	return atomic.LoadInt32(&s.g.paused) != 0
+}
+
+// gate waits while the work group is paused, unless the
+// context, ctx, is done or the work group is shut down.
+func (g *group) gate(ctx context.Context) {
+	if atomic.LoadInt32(&g.paused) == 0 {
+		return
+	}
+	g.mutex.Lock()
+	resume := g.resume
+	g.mutex.Unlock()
+	if resume == nil {
+		return
+	}
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	case <-g.stop:
+	}
+}
@@ -0,0 +1,126 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Pipeline processes items in a sequence of stages, each stage is
+// served by a number of workers and the stages are connected by
+// bounded channels, see NewPipeline.
+type Pipeline[T any] struct {
+	m      Manager
+	stages []pipelineStage[T]
+}
+
+type pipelineStage[T any] struct {
+	n  int
+	fn func(context.Context, T) (T, error)
+}
+
+// NewPipeline initializes a new pipeline that is managed by the manager,
+// m. The workers of all of the stages are the workers of a single work
+// group, and a worker stops at the first error of its stage function,
+// which is handled by the manager. With the default manager the first
+// error cancels the pipeline. If manager, m, is not provided then
+// DefaultManager is called to obtain the default manager.
+func NewPipeline[T any](m Manager) *Pipeline[T] {
+	return &Pipeline[T]{m: m}
+}
+
+// Stage adds a stage to the pipeline that calls the function, fn, for each
+// item on n workers, and passes the results to the next stage. The results
+// of the last stage are discarded. If n <= 0 then one worker is used.
+func (p *Pipeline[T]) Stage(n int, fn func(context.Context, T) (T, error)) *Pipeline[T] {
+	if n <= 0 {
+		n = 1
+	}
+	p.stages = append(p.stages, pipelineStage[T]{n: n, fn: fn})
+	return p
+}
+
+// Run passes the items provided by the channel, source, through the stages
+// of the pipeline, and waits for the channel to be closed and all of the
+// items to be processed, or for the pipeline to be cancelled, and returns
+// the error provided by the manager. The channel between two stages has a
+// capacity equal to the number of workers of the receiving stage.
+func (p *Pipeline[T]) Run(ctx context.Context, source <-chan T) error {
+	type worker struct {
+		stage   int
+		in      <-chan T
+		out     chan T
+		running *int32
+	}
+
+	var workers []worker
+	in := source
+	for i, s := range p.stages {
+		var out chan T
+		if i < len(p.stages)-1 {
+			out = make(chan T, p.stages[i+1].n)
+		}
+		running := int32(s.n)
+		for j := 0; j < s.n; j++ {
+			workers = append(workers, worker{stage: i, in: in, out: out, running: &running})
+		}
+		in = out
+	}
+
+	return WorkFor(ctx, NewUnlimited(), p.m, len(workers), func(ctx context.Context, index int) error {
+		w := workers[index]
+		defer func() {
+			if atomic.AddInt32(w.running, -1) == 0 {
+				// The last worker of the stage drains its input, so that
+				// the previous stage can complete, and closes its output.
+				drain(ctx, w.in)
+				if w.out != nil {
+					close(w.out)
+				}
+			}
+		}()
+		return p.stages[w.stage].run(ctx, w.in, w.out)
+	})
+}
+
+// run calls the function of the stage for the items provided by the
+// channel, in, until it is closed, and sends the results on the channel,
+// out, if any.
+func (s pipelineStage[T]) run(ctx context.Context, in <-chan T, out chan<- T) error {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return nil
+			}
+			r, err := s.fn(ctx, v)
+			if err != nil {
+				return err
+			}
+			if out == nil {
+				continue
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// drain receives from the channel, c, until it is closed
+// or the context, ctx, is done.
+func drain[T any](ctx context.Context, c <-chan T) {
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
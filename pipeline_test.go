@@ -0,0 +1,67 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+
+	source := make(chan int)
+	go func() {
+		defer close(source)
+		for i := 1; i <= 100; i++ {
+			source <- i
+		}
+	}()
+
+	var sum int64
+	err := NewPipeline[int](nil).
+		Stage(4, func(ctx context.Context, v int) (int, error) {
+			return v * 2, nil
+		}).
+		Stage(2, func(ctx context.Context, v int) (int, error) {
+			atomic.AddInt64(&sum, int64(v))
+			return v, nil
+		}).
+		Run(context.Background(), source)
+	if err != nil || sum != 10100 {
+		t.Fatalf("Expecting sum of 10100, got %d: %v", sum, err)
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	// the source is not closed until the test completes
+	done := make(chan struct{})
+	defer close(done)
+	source := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case source <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := NewPipeline[int](nil).
+		Stage(2, func(ctx context.Context, v int) (int, error) {
+			return v, nil
+		}).
+		Stage(2, func(ctx context.Context, v int) (int, error) {
+			if v == 10 {
+				return 0, failed
+			}
+			return v, nil
+		}).
+		Run(context.Background(), source)
+	if err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+}
@@ -0,0 +1,861 @@
+package workgroup
+
+import (
+	"container/heap"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TaskInfo describes a task that has been submitted
+// to a pool executer but has not yet been started.
+type TaskInfo struct {
+	// ID uniquely identifies the task within the pool.
+	ID uint64
+
+	// Enqueued is the time the task was submitted.
+	Enqueued time.Time
+
+	// Priority is the priority of the task, see WithPriority.
+	Priority int
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of the context, ctx, that assigns the
+// priority, p, to functions executed with the context. The workers of
+// work groups started with the context are submitted with the priority.
+// A priority pool starts pending tasks with higher priority first.
+func WithPriority(ctx context.Context, p int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) int {
+	p, _ := ctx.Value(priorityKey{}).(int)
+	return p
+}
+
+type task struct {
+	info     TaskInfo
+	ctx      context.Context
+	f        func(context.Context)
+	dequeued chan struct{}
+}
+
+// Pool is an executer that executes functions on a limited
+// number of goroutines. Submitted functions are queued
+// until a goroutine of the pool is available to execute them.
+// The number of goroutines is fixed unless the pool is resized
+// or configured with an idle timeout.
+type Pool struct {
+	mutex  sync.Mutex
+	queue  taskQueue
+	idle   []chan struct{}
+	closed bool
+	done   chan struct{}
+	nextID uint64
+	wg     sync.WaitGroup
+
+	slo        time.Duration
+	onSLO      func(TaskInfo, time.Duration)
+	violations uint64
+
+	spin    time.Duration
+	pending int32
+
+	size        int
+	workers     int
+	min         int
+	max         int
+	idleTimeout time.Duration
+
+	name     string
+	capacity int
+	reject   bool
+	space    *sync.Cond
+	prewarm  int
+	onPanic  func(TaskInfo, interface{})
+
+	init        func()
+	initialized int
+	readyAt     int
+	ready       chan struct{}
+
+	latency latencyRecorder
+	clock   Clock
+	counts  counters
+}
+
+// PoolOption configures optional behavior of a pool executer.
+type PoolOption func(*Pool)
+
+// WithWaitSLO configures a pool to check the time that each task
+// waited in the queue before starting. If the wait exceeds the
+// duration, slo, then the violation is counted and the optional
+// function, fn, is called with the task and the time it waited.
+// The function is called on the pool goroutine before the task
+// is started, so it should return promptly.
+func WithWaitSLO(slo time.Duration, fn func(TaskInfo, time.Duration)) PoolOption {
+	return func(p *Pool) {
+		p.slo = slo
+		p.onSLO = fn
+	}
+}
+
+// WithSpin configures the goroutines of a pool to spin for up to the
+// duration, d, waiting for a task when the queue is empty, before they
+// park. Spinning reduces the latency of starting tasks that arrive in
+// bursts at the cost of CPU time consumed while spinning. By default
+// the goroutines park immediately.
+func WithSpin(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.spin = d
+	}
+}
+
+// WithMinWorkers configures the minimum size of a pool, the pool
+// is never resized below, and idle goroutines are not reaped below,
+// n goroutines. The initial size of the pool is at least n.
+func WithMinWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.min = n
+	}
+}
+
+// WithMaxWorkers configures the maximum size of a pool, the pool
+// is never resized above n goroutines. If n <= 0 then the size of
+// the pool is not limited, which is the default.
+func WithMaxWorkers(n int) PoolOption {
+	return func(p *Pool) {
+		p.max = n
+	}
+}
+
+// WithIdleTimeout configures the goroutines of a pool, in excess of
+// the minimum size, to exit after waiting for a task for the duration,
+// d. Goroutines are started again, up to the size of the pool, when
+// tasks are queued and no goroutine is available to start them.
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// WithName configures the name of a pool for diagnostics. The
+// goroutines of a named pool are labeled with the name, using the
+// "workgroup.pool" profiler label.
+func WithName(name string) PoolOption {
+	return func(p *Pool) {
+		p.name = name
+	}
+}
+
+// WithQueueCapacity configures a pool to queue at most n tasks,
+// when the queue is full then Execute blocks until there is
+// capacity in the queue, unless configured by RejectWhenFull.
+// If n <= 0 then the queue is not limited, which is the default.
+func WithQueueCapacity(n int) PoolOption {
+	return func(p *Pool) {
+		p.capacity = n
+	}
+}
+
+// RejectWhenFull configures a pool with a limited queue, see
+// WithQueueCapacity, to reject a task when the queue is full rather
+// than block Execute. The function of a rejected task is called on the
+// calling goroutine with a cancelled context, and the work group of a
+// worker that is rejected handles it as if it completed with the error,
+// ErrQueueFull, so that overload is reported to the manager.
+func RejectWhenFull() PoolOption {
+	return func(p *Pool) {
+		p.reject = true
+	}
+}
+
+// WithPrewarm configures a pool to start n goroutines when it
+// is initialized, the remaining goroutines are started when tasks
+// are queued and no goroutine is available to start them. By
+// default all the goroutines of the pool are started.
+func WithPrewarm(n int) PoolOption {
+	return func(p *Pool) {
+		p.prewarm = n
+	}
+}
+
+// WithPanicHandler configures the goroutines of a pool to recover
+// from a panic in a task and call the function, fn, with the task
+// and the recovered value, the goroutine then continues to start
+// tasks. By default a panic in a task is not recovered and will
+// terminate the program, unless recovered by the task itself.
+func WithPanicHandler(fn func(TaskInfo, interface{})) PoolOption {
+	return func(p *Pool) {
+		p.onPanic = fn
+	}
+}
+
+// WithGoroutineInit configures the goroutines of a pool to call
+// the function, fn, when they start, before they start any tasks,
+// for example, to initialize per-goroutine state, see Ready.
+func WithGoroutineInit(fn func()) PoolOption {
+	return func(p *Pool) {
+		p.init = fn
+	}
+}
+
+// SchedulingOrder specifies the order that
+// a pool starts pending tasks, see WithSchedulingOrder.
+type SchedulingOrder int
+
+const (
+	// FIFO starts the pending tasks in the
+	// order that they were submitted, the default.
+	FIFO SchedulingOrder = iota
+
+	// LIFO starts the most recently submitted pending task first,
+	// which can improve cache locality and the latency of the tasks
+	// that are started, at the cost of tasks that wait longer.
+	LIFO
+)
+
+// WithSchedulingOrder configures the order that a pool starts pending
+// tasks. It replaces the order of a priority pool, see NewPriorityPool.
+func WithSchedulingOrder(order SchedulingOrder) PoolOption {
+	return func(p *Pool) {
+		p.queue.less = nil
+		p.queue.lifo = order == LIFO
+	}
+}
+
+// WithTaskComparator configures a pool to start the pending tasks in
+// the order defined by the function, less, which reports whether the
+// task, a, is started before the task, b. Ties are broken by the order
+// that the tasks were submitted. It replaces the order of a priority
+// pool, see NewPriorityPool.
+func WithTaskComparator(less func(a, b TaskInfo) bool) PoolOption {
+	return func(p *Pool) {
+		p.queue.lifo = false
+		p.queue.less = func(a, b *task) bool {
+			if less(a.info, b.info) {
+				return true
+			}
+			if less(b.info, a.info) {
+				return false
+			}
+			return a.info.ID < b.info.ID
+		}
+	}
+}
+
+// NewPool initializes a new pool executer that will execute
+// functions on n goroutines, see Resize. If n <= 0 then
+// the values in DefaultLimit is used. Note that the provided
+// context must be cancelled, or the pool closed, to ensure that
+// the pool releases all resources. Optional behavior is
+// configured with opts. Time is measured by the clock of
+// the context, see WithClock.
+func NewPool(ctx context.Context, n int, opts ...PoolOption) *Pool {
+	return newPool(ctx, n, nil, opts)
+}
+
+// NewPriorityPool initializes a new pool executer, see NewPool, that
+// starts pending tasks in order of priority, highest first, and tasks
+// of equal priority in the order they were submitted. The priority of
+// a task is provided by the context configured by WithPriority, the
+// workers of a work group can be given their own priorities with a
+// context configured by WithContextDecorator.
+func NewPriorityPool(ctx context.Context, n int, opts ...PoolOption) *Pool {
+	return newPool(ctx, n, byPriority, opts)
+}
+
+func newPool(ctx context.Context, n int, less func(a, b *task) bool, opts []PoolOption) *Pool {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	p := &Pool{prewarm: -1, clock: ClockFrom(ctx), ready: make(chan struct{}), done: make(chan struct{})}
+	p.queue.less = less
+	p.space = sync.NewCond(&p.mutex)
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if ctx != nil && ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				p.close()
+			case <-p.done:
+			}
+		}()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.size = p.clamp(n)
+	prewarm := p.size
+	if p.prewarm >= 0 && p.prewarm < prewarm {
+		prewarm = p.prewarm
+	}
+	p.prestart(prewarm)
+	return p
+}
+
+// Prestart starts goroutines of the pool, so that at least n goroutines,
+// up to the size of the pool, are running, rather than starting them when
+// tasks are submitted, see Ready.
+func (p *Pool) Prestart(n int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.closed {
+		p.prestart(n)
+	}
+}
+
+// prestart starts goroutines up to n, the mutex must be held.
+func (p *Pool) prestart(n int) {
+	if n > p.size {
+		n = p.size
+	}
+	for p.workers < n {
+		p.spawn()
+	}
+
+	if n > p.readyAt {
+		p.readyAt = n
+		if p.initialized < n {
+			select {
+			case <-p.ready:
+				p.ready = make(chan struct{})
+			default:
+			}
+		}
+	}
+	p.checkReady()
+}
+
+// Ready returns a channel that is closed when the goroutines started when
+// the pool was initialized, and by Prestart, have been initialized, see
+// WithGoroutineInit. If Prestart requests more goroutines after the channel
+// is closed, then a new channel is returned until they are initialized.
+func (p *Pool) Ready() <-chan struct{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.ready
+}
+
+// checkReady closes the ready channel when the
+// goroutines are initialized, the mutex must be held.
+func (p *Pool) checkReady() {
+	if p.initialized < p.readyAt {
+		return
+	}
+	select {
+	case <-p.ready:
+	default:
+		close(p.ready)
+	}
+}
+
+// Name returns the name of the pool, see WithName.
+func (p *Pool) Name() string {
+	return p.name
+}
+
+// Resize changes the size of the pool to n goroutines, limited by the
+// minimum and maximum sizes of the pool. Goroutines are started when the
+// pool grows, and surplus goroutines exit once they complete their current
+// task when the pool shrinks. If n <= 0 then the value in DefaultLimit is
+// used. Resizing a closed pool has no effect.
+func (p *Pool) Resize(n int) {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.size = p.clamp(n)
+	for p.workers < p.size {
+		p.spawn()
+	}
+	for i := p.size; i < p.workers && len(p.idle) > 0; i++ {
+		p.wake()
+	}
+}
+
+// Limit returns the size of the pool, see Resize.
+func (p *Pool) Limit() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.size
+}
+
+// SetLimit resizes the pool to n goroutines, see Resize.
+func (p *Pool) SetLimit(n int) {
+	p.Resize(n)
+}
+
+// Workers returns the number of goroutines of the pool.
+func (p *Pool) Workers() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.workers
+}
+
+// clamp limits the size, n, to the minimum and maximum sizes of the pool.
+func (p *Pool) clamp(n int) int {
+	if p.max > 0 && n > p.max {
+		n = p.max
+	}
+	if n < p.min {
+		n = p.min
+	}
+	return n
+}
+
+// spawn starts a goroutine of the pool, the mutex must be held.
+func (p *Pool) spawn() {
+	p.workers++
+	p.wg.Add(1)
+	go p.run()
+}
+
+// Execute submits the function, f, to the pool and returns once it has
+// been queued, a goroutine of the pool starts it in the scheduling order
+// of the pool. If the queue is limited, see WithQueueCapacity, then
+// Execute blocks until there is capacity in the queue. The workers of
+// a work group are submitted without waiting for the workers before
+// them to start, so that the order of the pool applies to them, the
+// work group waits for them to complete. If the pool has been closed
+// then f is called on the calling goroutine.
+func (p *Pool) Execute(ctx context.Context, f func(context.Context)) {
+	p.mutex.Lock()
+	if p.reject && p.capacity > 0 && p.queue.Len() >= p.capacity && !p.closed {
+		p.mutex.Unlock()
+		f(rejected(ctx, ErrQueueFull))
+		return
+	}
+	for p.capacity > 0 && p.queue.Len() >= p.capacity && !p.closed {
+		p.space.Wait()
+	}
+	if p.closed {
+		p.mutex.Unlock()
+		f(ctx)
+		return
+	}
+
+	p.enqueue(ctx, f)
+	p.mutex.Unlock()
+}
+
+// enqueue queues a task for the function, f, and starts or
+// wakes a goroutine to start it, the mutex must be held.
+func (p *Pool) enqueue(ctx context.Context, f func(context.Context)) *task {
+	p.nextID++
+	t := &task{
+		info: TaskInfo{
+			ID:       p.nextID,
+			Enqueued: p.clock.Now(),
+			Priority: priorityFrom(ctx),
+		},
+		ctx:      ctx,
+		f:        f,
+		dequeued: make(chan struct{}),
+	}
+	p.queue.push(t)
+	p.queued()
+	if len(p.idle) == 0 && p.workers < p.size {
+		p.spawn()
+	} else {
+		p.wake()
+	}
+	return t
+}
+
+// TryExecute submits the function, f, to the pool if a goroutine of
+// the pool is available to start it without waiting for the other
+// tasks, and reports whether it was submitted. When it is submitted,
+// TryExecute blocks until the goroutine has started it.
+func (p *Pool) TryExecute(ctx context.Context, f func(context.Context)) bool {
+	p.mutex.Lock()
+	if p.closed || p.queue.Len() > 0 || (len(p.idle) == 0 && p.workers >= p.size) {
+		p.mutex.Unlock()
+		return false
+	}
+	t := p.enqueue(ctx, f)
+	p.mutex.Unlock()
+
+	<-t.dequeued
+	return true
+}
+
+// PendingTasks returns information about the tasks that
+// have been submitted to the pool but not yet started,
+// in the order that they will be started.
+func (p *Pool) PendingTasks() []TaskInfo {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.queue.infos()
+}
+
+// CancelTask removes the pending task with the given ID from
+// the queue and reports whether the task was found. The function
+// of a cancelled task is still called, on a new goroutine and with
+// a cancelled context, so that the work group it belongs to can
+// complete. Tasks that have already started cannot be cancelled.
+func (p *Pool) CancelTask(id uint64) bool {
+	p.mutex.Lock()
+	t := p.queue.remove(id)
+	p.queued()
+	p.mutex.Unlock()
+
+	if t == nil {
+		return false
+	}
+	close(t.dequeued)
+
+	ctx, cancel := context.WithCancel(t.ctx)
+	cancel()
+	go t.f(ctx)
+	return true
+}
+
+// Stats returns the runtime statistics of the pool.
+func (p *Pool) Stats() Stats {
+	p.mutex.Lock()
+	queued := p.queue.Len()
+	p.mutex.Unlock()
+
+	s := p.counts.stats()
+	s.Queued = queued
+	s.Latency = p.latency.percentiles()
+	return s
+}
+
+// SLOViolations returns the number of tasks that waited
+// in the queue longer than the SLO configured by WithWaitSLO.
+func (p *Pool) SLOViolations() uint64 {
+	return atomic.LoadUint64(&p.violations)
+}
+
+// queued records the length of the queue for spinning
+// goroutines to observe, the mutex must be held.
+func (p *Pool) queued() {
+	atomic.StoreInt32(&p.pending, int32(p.queue.Len()))
+	if p.capacity > 0 && p.queue.Len() < p.capacity {
+		p.space.Signal()
+	}
+}
+
+// wake signals one idle goroutine, the mutex must be held.
+func (p *Pool) wake() {
+	if n := len(p.idle); n > 0 {
+		p.idle[n-1] <- struct{}{}
+		p.idle = p.idle[:n-1]
+	}
+}
+
+// Close closes the pool and waits for the queued tasks to be
+// started and for the goroutines of the pool to terminate.
+// After the pool is closed, functions are executed on the
+// calling goroutine. The result is always nil.
+func (p *Pool) Close() error {
+	p.close()
+	p.wg.Wait()
+	return nil
+}
+
+// Shutdown closes the pool, see Close, but waits at most until
+// the context, ctx, is done, in which case the error of the
+// context is returned and the pool continues to terminate.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.close()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.closed {
+		close(p.done)
+	}
+	p.closed = true
+	p.space.Broadcast()
+	for _, w := range p.idle {
+		w <- struct{}{}
+	}
+	p.idle = nil
+}
+
+func (p *Pool) run() {
+	defer p.wg.Done()
+	if p.init != nil {
+		p.init()
+	}
+
+	p.mutex.Lock()
+	p.initialized++
+	p.checkReady()
+	p.mutex.Unlock()
+	defer func() {
+		p.mutex.Lock()
+		p.initialized--
+		p.mutex.Unlock()
+	}()
+
+	if p.name != "" {
+		pprof.Do(context.Background(), pprof.Labels("workgroup.pool", p.name), func(context.Context) {
+			p.loop()
+		})
+		return
+	}
+	p.loop()
+}
+
+func (p *Pool) loop() {
+	wake := make(chan struct{}, 1)
+	for {
+		t := p.next(wake)
+		if t == nil {
+			return
+		}
+		wait := p.clock.Now().Sub(t.info.Enqueued)
+		p.latency.record(wait)
+		p.checkSLO(t, wait)
+		p.start(t)
+	}
+}
+
+// start calls the function of the task, t, and
+// recovers from a panic if a handler is configured.
+func (p *Pool) start(t *task) {
+	p.counts.start()
+	defer p.counts.finish()
+	if p.onPanic != nil {
+		defer func() {
+			if v := recover(); v != nil {
+				p.onPanic(t.info, v)
+			}
+		}()
+	}
+	t.f(t.ctx)
+}
+
+func (p *Pool) checkSLO(t *task, wait time.Duration) {
+	if p.slo <= 0 {
+		return
+	}
+	if wait > p.slo {
+		atomic.AddUint64(&p.violations, 1)
+		if p.onSLO != nil {
+			p.onSLO(t.info, wait)
+		}
+	}
+}
+
+// next waits for the next queued task, the result is nil if the
+// goroutine is to exit, either the pool is closed and the queue is
+// empty or the goroutine is surplus to the size of the pool.
+func (p *Pool) next(wake chan struct{}) *task {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	spun := p.spin <= 0
+	for {
+		if p.workers > p.size {
+			p.workers--
+			return nil
+		}
+		if p.queue.Len() > 0 {
+			break
+		}
+		if p.closed {
+			p.workers--
+			return nil
+		}
+		if !spun {
+			spun = true
+			p.mutex.Unlock()
+			p.spinWait()
+			p.mutex.Lock()
+			continue
+		}
+
+		reap := p.idleTimeout > 0 && p.workers > p.min
+		p.idle = append(p.idle, wake)
+		p.mutex.Unlock()
+		timeout := p.park(wake, reap)
+		p.mutex.Lock()
+
+		if timeout {
+			if !p.unidle(wake) {
+				// signalled after the timeout
+				<-wake
+			} else if p.workers > p.min && p.queue.Len() == 0 {
+				p.workers--
+				return nil
+			}
+		}
+	}
+
+	t := p.queue.pop()
+	p.queued()
+	close(t.dequeued)
+	return t
+}
+
+// park waits to be signalled and reports whether it
+// timed out waiting for the idle timeout of the pool.
+func (p *Pool) park(wake chan struct{}, reap bool) bool {
+	if !reap {
+		<-wake
+		return false
+	}
+
+	timer := p.clock.NewTimer(p.idleTimeout)
+	defer timer.Stop()
+	select {
+	case <-wake:
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+// unidle removes the wake channel from the idle list and reports
+// whether it was found, the mutex must be held.
+func (p *Pool) unidle(wake chan struct{}) bool {
+	for i, w := range p.idle {
+		if w == wake {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// spinWait spins until a task is queued or the spin duration elapses.
+func (p *Pool) spinWait() {
+	deadline := time.Now().Add(p.spin)
+	for atomic.LoadInt32(&p.pending) == 0 && time.Now().Before(deadline) {
+		runtime.Gosched()
+	}
+}
+
+func byPriority(a, b *task) bool {
+	if a.info.Priority != b.info.Priority {
+		return a.info.Priority > b.info.Priority
+	}
+	return a.info.ID < b.info.ID
+}
+
+// taskQueue holds the pending tasks of a pool. The tasks are
+// in first-in first-out order, or last-in first-out order if
+// lifo is set, unless an ordering is provided by the less
+// function, then the tasks are kept in a heap.
+type taskQueue struct {
+	tasks []*task
+	less  func(a, b *task) bool
+	lifo  bool
+}
+
+func (q *taskQueue) Len() int           { return len(q.tasks) }
+func (q *taskQueue) Less(i, j int) bool { return q.less(q.tasks[i], q.tasks[j]) }
+func (q *taskQueue) Swap(i, j int)      { q.tasks[i], q.tasks[j] = q.tasks[j], q.tasks[i] }
+
+func (q *taskQueue) Push(x interface{}) {
+	q.tasks = append(q.tasks, x.(*task))
+}
+
+func (q *taskQueue) Pop() interface{} {
+	n := len(q.tasks) - 1
+	t := q.tasks[n]
+	q.tasks[n] = nil
+	q.tasks = q.tasks[:n]
+	return t
+}
+
+func (q *taskQueue) push(t *task) {
+	if q.less != nil {
+		heap.Push(q, t)
+		return
+	}
+	q.tasks = append(q.tasks, t)
+}
+
+func (q *taskQueue) pop() *task {
+	if q.less != nil {
+		return heap.Pop(q).(*task)
+	}
+	if q.lifo {
+		return q.Pop().(*task)
+	}
+	t := q.tasks[0]
+	q.tasks[0] = nil
+	q.tasks = q.tasks[1:]
+	return t
+}
+
+// remove removes the task with the given ID, or returns nil if not found.
+func (q *taskQueue) remove(id uint64) *task {
+	for i, t := range q.tasks {
+		if t.info.ID == id {
+			if q.less != nil {
+				heap.Remove(q, i)
+			} else {
+				q.tasks = append(q.tasks[:i], q.tasks[i+1:]...)
+			}
+			return t
+		}
+	}
+	return nil
+}
+
+// infos returns the information of the tasks in the order they will be started.
+func (q *taskQueue) infos() []TaskInfo {
+	tasks := make([]*task, len(q.tasks))
+	copy(tasks, q.tasks)
+	if q.less != nil {
+		sort.Slice(tasks, func(i, j int) bool {
+			return q.less(tasks[i], tasks[j])
+		})
+	} else if q.lifo {
+		for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+			tasks[i], tasks[j] = tasks[j], tasks[i]
+		}
+	}
+
+	infos := make([]TaskInfo, len(tasks))
+	for i, t := range tasks {
+		infos[i] = t.info
+	}
+	return infos
+}
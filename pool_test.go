@@ -0,0 +1,551 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolCancelTask(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Execute(ctx, func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+	<-started
+
+	var mutex sync.Mutex
+	cancelled := make(map[int]bool)
+
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		index := i
+		go func() {
+			p.Execute(ctx, func(ctx context.Context) {
+				defer wg.Done()
+				mutex.Lock()
+				defer mutex.Unlock()
+				cancelled[index] = ctx.Err() != nil
+			})
+		}()
+	}
+
+	var pending []TaskInfo
+	for len(pending) < 3 {
+		time.Sleep(time.Millisecond)
+		pending = p.PendingTasks()
+	}
+
+	if !p.CancelTask(pending[1].ID) {
+		t.Fatalf("Pending task %d not found", pending[1].ID)
+	}
+	if p.CancelTask(pending[1].ID) {
+		t.Fatalf("Pending task %d cancelled twice", pending[1].ID)
+	}
+	if n := len(p.PendingTasks()); n != 2 {
+		t.Fatalf("Expecting 2 pending tasks, got %d", n)
+	}
+
+	close(block)
+	wg.Wait()
+
+	ncancelled := 0
+	for _, c := range cancelled {
+		if c {
+			ncancelled++
+		}
+	}
+	if len(cancelled) != 3 || ncancelled != 1 {
+		t.Fatalf("Expecting 3 tasks with 1 cancelled: %v", cancelled)
+	}
+}
+
+func TestPoolWaitSLO(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mutex sync.Mutex
+	var waits []time.Duration
+
+	p := NewPool(ctx, 1, WithWaitSLO(5*time.Millisecond, func(info TaskInfo, wait time.Duration) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		waits = append(waits, wait)
+	}))
+
+	WorkFor(ctx, p, nil, 4, func(ctx context.Context, index int) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	// The first task is started without waiting in
+	// the queue, the remaining tasks wait ~10ms each.
+	if n := p.SLOViolations(); n != 3 {
+		t.Fatalf("Expecting 3 SLO violations, got %d", n)
+	}
+	for _, w := range waits {
+		if w <= 5*time.Millisecond {
+			t.Fatalf("SLO hook called with wait %s", w)
+		}
+	}
+}
+
+func TestPriorityPool(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPriorityPool(ctx, 1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Execute(ctx, func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+	<-started
+
+	var mutex sync.Mutex
+	var order []int
+
+	wg := sync.WaitGroup{}
+	for _, priority := range []int{1, 3, 2} {
+		wg.Add(1)
+		pctx := WithPriority(ctx, priority)
+		go WorkFor(pctx, p, nil, 1, func(ctx context.Context, index int) error {
+			defer wg.Done()
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, priorityFrom(ctx))
+			return nil
+		})
+	}
+
+	for len(p.PendingTasks()) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	if pending := p.PendingTasks(); pending[0].Priority != 3 || pending[2].Priority != 1 {
+		t.Fatalf("Pending tasks are not in priority order: %v", pending)
+	}
+
+	close(block)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Fatalf("Tasks were not started in priority order: %v", order)
+	}
+}
+
+func TestPriorityPoolWork(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPriorityPool(ctx, 1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Execute(ctx, func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+	<-started
+
+	// the workers of a single work group are started by priority
+	var order []int
+	done := make(chan error)
+	go func() {
+		wctx := WithContextDecorator(ctx, func(ctx context.Context, index int) context.Context {
+			return WithPriority(ctx, index%3)
+		})
+		done <- WorkFor(wctx, p, nil, 6, func(ctx context.Context, index int) error {
+			order = append(order, index)
+			return nil
+		})
+	}()
+	for len(p.PendingTasks()) < 6 {
+		time.Sleep(time.Millisecond)
+	}
+	close(block)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(order) != "[2 5 1 4 0 3]" {
+		t.Fatalf("Workers were not started in priority order: %v", order)
+	}
+}
+
+func TestPoolSchedulingOrder(t *testing.T) {
+
+	submit := func(p *Pool, n int) []int {
+		block := make(chan struct{})
+		started := make(chan struct{})
+		p.Execute(context.Background(), func(ctx context.Context) {
+			close(started)
+			<-block
+		})
+		<-started
+
+		var mutex sync.Mutex
+		var order []int
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			id := i
+			go p.Execute(WithPriority(context.Background(), i%2), func(ctx context.Context) {
+				defer wg.Done()
+				mutex.Lock()
+				defer mutex.Unlock()
+				order = append(order, id)
+			})
+			for len(p.PendingTasks()) < i+1 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		close(block)
+		wg.Wait()
+		return order
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 1, WithSchedulingOrder(LIFO))
+	if pending := len(p.PendingTasks()); pending != 0 {
+		t.Fatalf("Unexpected pending tasks: %d", pending)
+	}
+	if order := submit(p, 4); fmt.Sprint(order) != "[3 2 1 0]" {
+		t.Fatalf("Tasks were not started in LIFO order: %v", order)
+	}
+
+	p = NewPool(ctx, 1, WithTaskComparator(func(a, b TaskInfo) bool {
+		return a.Priority < b.Priority
+	}))
+	if order := submit(p, 4); fmt.Sprint(order) != "[0 2 1 3]" {
+		t.Fatalf("Tasks were not started in the order of the comparator: %v", order)
+	}
+}
+
+func TestPoolSchedulingOrderWork(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 1, WithSchedulingOrder(LIFO))
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Execute(ctx, func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+	<-started
+
+	// the workers of a single work group are queued together
+	var order []int
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(ctx, p, nil, 6, func(ctx context.Context, index int) error {
+			order = append(order, index)
+			return nil
+		})
+	}()
+	for len(p.PendingTasks()) < 6 {
+		time.Sleep(time.Millisecond)
+	}
+	close(block)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(order) != "[5 4 3 2 1 0]" {
+		t.Fatalf("Workers were not started in LIFO order: %v", order)
+	}
+}
+
+func TestPoolShutdown(t *testing.T) {
+
+	p := NewPool(context.Background(), 2)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Execute(context.Background(), func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expecting shutdown to time out, got %v", err)
+	}
+
+	executed := false
+	p.Execute(context.Background(), func(ctx context.Context) {
+		executed = true
+	})
+	if !executed {
+		t.Fatalf("Expecting function executed by the caller after close")
+	}
+
+	close(block)
+	if err := p.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestPoolCloseContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the pools do not wait for the context once they are closed
+	n := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		p := NewPool(ctx, 1)
+		if i%2 == 0 {
+			p.Close()
+		} else {
+			p.Shutdown(ctx)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if leaked := runtime.NumGoroutine() - n; leaked > 0 {
+		t.Fatalf("Expecting no goroutines after the pools are closed, got %d", leaked)
+	}
+}
+
+func TestPoolSpin(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 2, WithSpin(time.Millisecond))
+
+	var mutex sync.Mutex
+	count := 0
+	for i := 0; i < 10; i++ {
+		WorkFor(ctx, p, nil, 10, func(ctx context.Context, index int) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			count++
+			return nil
+		})
+		time.Sleep(time.Duration(i%3) * time.Millisecond)
+	}
+	if count != 100 {
+		t.Fatalf("Expecting 100 workers executed, got %d", count)
+	}
+}
+
+// benchmarkPoolLatency submits one task at a time and waits for it to
+// complete, so that each task is started by a goroutine that is waiting.
+func benchmarkPoolLatency(b *testing.B, opts ...PoolOption) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 1, opts...)
+	done := make(chan struct{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Execute(ctx, func(ctx context.Context) {
+			done <- struct{}{}
+		})
+		<-done
+	}
+}
+
+func BenchmarkPoolLatencyPark(b *testing.B) {
+	benchmarkPoolLatency(b)
+}
+
+func BenchmarkPoolLatencySpin(b *testing.B) {
+	benchmarkPoolLatency(b, WithSpin(50*time.Microsecond))
+}
+
+func TestPoolResize(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 2, WithMinWorkers(1), WithMaxWorkers(4), WithIdleTimeout(10*time.Millisecond))
+
+	var running, max int32
+	work := func(ctx context.Context, index int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}
+
+	p.Resize(10)
+	if n := p.Workers(); n != 4 {
+		t.Fatalf("Expecting 4 workers after resize, got %d", n)
+	}
+	WorkFor(ctx, p, nil, 16, work)
+	if max != 4 {
+		t.Fatalf("Expecting 4 concurrent workers, got %d", max)
+	}
+
+	for p.Workers() > 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	atomic.StoreInt32(&max, 0)
+	WorkFor(ctx, p, nil, 16, work)
+	if max != 4 {
+		t.Fatalf("Expecting 4 concurrent workers after reaping, got %d", max)
+	}
+
+	p.Resize(2)
+	atomic.StoreInt32(&max, 0)
+	WorkFor(ctx, p, nil, 16, work)
+	if max > 2 {
+		t.Fatalf("Expecting at most 2 concurrent workers, got %d", max)
+	}
+}
+
+func TestPoolOptions(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var panics int32
+	p := NewPool(ctx, 2,
+		WithName("test"),
+		WithPrewarm(0),
+		WithQueueCapacity(1),
+		WithPanicHandler(func(info TaskInfo, v interface{}) {
+			atomic.AddInt32(&panics, 1)
+		}),
+	)
+	if p.Name() != "test" {
+		t.Fatalf("Expecting pool name 'test', got %q", p.Name())
+	}
+	if n := p.Workers(); n != 0 {
+		t.Fatalf("Expecting no prewarmed workers, got %d", n)
+	}
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		p.Execute(ctx, func(ctx context.Context) {
+			started <- struct{}{}
+			<-block
+			panic("task panic")
+		})
+	}
+	<-started
+	<-started
+
+	submitted := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			p.Execute(ctx, func(ctx context.Context) {})
+			submitted <- struct{}{}
+		}()
+	}
+	for len(p.PendingTasks()) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if n := len(p.PendingTasks()); n != 1 {
+		t.Fatalf("Expecting queue capacity of 1, got %d pending", n)
+	}
+
+	close(block)
+	<-submitted
+	<-submitted
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := atomic.LoadInt32(&panics); n != 2 {
+		t.Fatalf("Expecting 2 recovered panics, got %d", n)
+	}
+}
+
+func TestPoolStatsLatency(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var report Report
+	p := NewPool(ctx, 1)
+	WorkFor(WithReport(ctx, func(r Report) { report = r }), p, nil, 10, func(ctx context.Context, index int) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+
+	// Each worker waits for the preceding worker to complete.
+	stats := p.Stats()
+	if stats.Latency.P99 < time.Millisecond || stats.Latency.P50 > stats.Latency.P99 {
+		t.Fatalf("Unexpected pool latency percentiles: %+v", stats.Latency)
+	}
+	if report.Latency.P99 < time.Millisecond {
+		t.Fatalf("Unexpected report latency percentiles: %+v", report.Latency)
+	}
+}
+
+func TestPoolPrestartReady(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var inits int32
+	release := make(chan struct{})
+	p := NewPool(ctx, 4, WithPrewarm(0), WithGoroutineInit(func() {
+		atomic.AddInt32(&inits, 1)
+		<-release
+	}))
+
+	select {
+	case <-p.Ready():
+	default:
+		t.Fatalf("Expecting pool without prewarmed goroutines to be ready")
+	}
+
+	p.Prestart(3)
+	ready := p.Ready()
+	select {
+	case <-ready:
+		t.Fatalf("Expecting pool not ready before goroutines are initialized")
+	default:
+	}
+
+	close(release)
+	select {
+	case <-ready:
+	case <-time.After(time.Second):
+		t.Fatalf("Expecting pool ready after goroutines are initialized")
+	}
+	if n := atomic.LoadInt32(&inits); n != 3 {
+		t.Fatalf("Expecting 3 goroutines initialized, got %d", n)
+	}
+}
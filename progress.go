@@ -0,0 +1,229 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProgressUpdate is the progress of a work group sent by ProgressChan.
+type ProgressUpdate struct {
+	Completed int
+	Total     int
+
+	// Final reports whether the update is the final update,
+	// which is sent when the work group completes.
+	Final bool
+}
+
+type progress struct {
+	m         Manager
+	fn        func(completed, total int)
+	mutex     sync.Mutex
+	completed int
+	total     int
+}
+
+// Progress wraps a Manager, m, and calls the function, fn, as each worker
+// completes with the number of workers that have completed and the total
+// number of workers of the work group. The total is known by Work and
+// WorkFor when the group starts, for WorkChan the total is the number of
+// workers submitted so far. The function is not called concurrently,
+// and the number of completed workers increases with each call. If
+// manager, m, is not provided then DefaultManager is called to obtain
+// the default manager.
+func Progress(m Manager, fn func(completed, total int)) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &progress{m: m, fn: fn}
+}
+
+// ProgressChan wraps a Manager, m, like Progress, but sends the progress
+// to the channel, ch. The updates are dropped if the channel is not ready
+// to receive, so that progress reporting does not slow down the workers,
+// except for the final update, which is always sent when the work group
+// completes, see ProgressUpdate. The channel is not closed.
+func ProgressChan(m Manager, ch chan<- ProgressUpdate) Manager {
+	p := &progressChan{ch: ch}
+	p.progress = Progress(m, func(completed, total int) {
+		select {
+		case ch <- ProgressUpdate{Completed: completed, Total: total}:
+		default:
+		}
+	}).(*progress)
+	return p
+}
+
+type progressChan struct {
+	*progress
+	ch   chan<- ProgressUpdate
+	once sync.Once
+}
+
+// Error sends the final update, the work group has completed.
+func (p *progressChan) Error() error {
+	p.once.Do(func() {
+		p.mutex.Lock()
+		u := ProgressUpdate{Completed: p.completed, Total: p.total, Final: true}
+		p.mutex.Unlock()
+		p.ch <- u
+	})
+	return p.m.Error()
+}
+
+func (p *progress) Error() error {
+	return p.m.Error()
+}
+
+func (p *progress) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := p.m.Manage(ctx, c, idx, err)
+
+	total := 0
+	if g, ok := ctx.Value(groupKey{}).(*group); ok {
+		total = g.total()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.completed++
+	p.total = total
+	p.fn(p.completed, total)
+	return n
+}
+
+// ProgressStats is a snapshot of the progress of a work group,
+// see ProgressTracker.
+type ProgressStats struct {
+	// Completed is the number of workers that have completed.
+	Completed int
+
+	// Total is the total number of workers, see Progress.
+	Total int
+
+	// Elapsed is the time since the first worker completed.
+	Elapsed time.Duration
+
+	// Rate is the average number of workers completed per second.
+	Rate float64
+
+	// Throughput is the moving average of the number
+	// of workers completed per second.
+	Throughput float64
+
+	// ETA is the estimated time remaining, based on the throughput,
+	// or zero if it can not be estimated.
+	ETA time.Duration
+}
+
+// progressAlpha is the weight of the latest
+// sample in the moving average of the throughput.
+const progressAlpha = 0.3
+
+// ProgressTracker tracks the progress of a work group and computes the
+// completion rate, the moving average throughput and the estimated time
+// remaining, as commonly reported by command line tools.
+type ProgressTracker struct {
+	mutex      sync.Mutex
+	interval   time.Duration
+	clock      Clock
+	completed  int
+	total      int
+	start      time.Time
+	now        time.Time
+	sampled    time.Time
+	scompleted int
+	throughput float64
+}
+
+// NewProgressTracker returns a tracker with a throughput that is
+// averaged exponentially over samples taken at the interval. If
+// interval <= 0 then an interval of one second is used.
+func NewProgressTracker(interval time.Duration) *ProgressTracker {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &ProgressTracker{interval: interval}
+}
+
+type trackerManager struct {
+	t *ProgressTracker
+	m Manager
+}
+
+// Manager wraps a Manager, m, and reports the progress of the work
+// group to the tracker, see Progress. If manager, m, is not provided
+// then DefaultManager is called to obtain the default manager.
+func (t *ProgressTracker) Manager(m Manager) Manager {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &trackerManager{t: t, m: m}
+}
+
+func (m *trackerManager) Error() error {
+	return m.m.Error()
+}
+
+func (m *trackerManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := m.m.Manage(ctx, c, idx, err)
+
+	total := 0
+	if g, ok := ctx.Value(groupKey{}).(*group); ok {
+		total = g.total()
+	}
+	m.t.update(ClockFrom(ctx), total)
+	return n
+}
+
+func (t *ProgressTracker) update(clock Clock, total int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := clock.Now()
+	if t.clock == nil {
+		t.clock = clock
+		t.start = now
+		t.sampled = now
+	}
+	t.completed++
+	t.total = total
+	t.now = now
+
+	if elapsed := now.Sub(t.sampled); elapsed >= t.interval {
+		rate := float64(t.completed-t.scompleted) / elapsed.Seconds()
+		if t.scompleted == 0 {
+			t.throughput = rate
+		} else {
+			t.throughput = progressAlpha*rate + (1-progressAlpha)*t.throughput
+		}
+		t.sampled = now
+		t.scompleted = t.completed
+	}
+}
+
+// Stats returns a snapshot of the progress.
+func (t *ProgressTracker) Stats() ProgressStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	s := ProgressStats{
+		Completed:  t.completed,
+		Total:      t.total,
+		Throughput: t.throughput,
+	}
+	if t.clock == nil {
+		return s
+	}
+	s.Elapsed = t.clock.Now().Sub(t.start)
+	if s.Elapsed > 0 {
+		s.Rate = float64(t.completed) / s.Elapsed.Seconds()
+	}
+	if s.Throughput == 0 {
+		s.Throughput = s.Rate
+	}
+	if remaining := t.total - t.completed; remaining > 0 && s.Throughput > 0 {
+		s.ETA = time.Duration(float64(remaining) / s.Throughput * float64(time.Second))
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+
+	var updates []ProgressUpdate
+	m := Progress(nil, func(completed, total int) {
+		updates = append(updates, ProgressUpdate{Completed: completed, Total: total})
+	})
+
+	WorkFor(context.Background(), nil, m, 10, func(ctx context.Context, index int) error {
+		return nil
+	})
+
+	if len(updates) != 10 {
+		t.Fatalf("Expecting 10 progress updates, got %d", len(updates))
+	}
+	for i, u := range updates {
+		if u.Completed != i+1 || u.Total != 10 {
+			t.Fatalf("Unexpected progress update %d: %+v", i, u)
+		}
+	}
+}
+
+func TestProgressChan(t *testing.T) {
+
+	ch := make(chan ProgressUpdate, 1)
+	m := ProgressChan(nil, ch)
+
+	done := make(chan struct{})
+	var last ProgressUpdate
+	go func() {
+		defer close(done)
+		for u := range ch {
+			last = u
+			if u.Final {
+				return
+			}
+		}
+	}()
+
+	WorkFor(context.Background(), nil, m, 100, func(ctx context.Context, index int) error {
+		return nil
+	})
+	<-done
+
+	if last.Completed != 100 || last.Total != 100 {
+		t.Fatalf("Expecting final progress update, got %+v", last)
+	}
+}
+
+// countedManager closes the channel, done, once n workers are managed.
+type countedManager struct {
+	Manager
+	n    int32
+	done chan struct{}
+}
+
+func (m *countedManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	n := m.Manager.Manage(ctx, c, idx, err)
+	if atomic.AddInt32(&m.n, -1) == 0 {
+		close(m.done)
+	}
+	return n
+}
+
+func TestProgressChanFinal(t *testing.T) {
+
+	// the updates of the workers are dropped without a receiver
+	ch := make(chan ProgressUpdate)
+	workers := make(chan Worker, 3)
+	for i := 0; i < 3; i++ {
+		workers <- func(ctx context.Context) error {
+			return nil
+		}
+	}
+	close(workers)
+
+	managed := make(chan struct{})
+	m := &countedManager{Manager: ProgressChan(nil, ch), n: 3, done: managed}
+	done := make(chan error)
+	go func() {
+		done <- WorkChan(context.Background(), NewSerial(), m, workers)
+	}()
+	<-managed
+	if u := <-ch; !u.Final || u.Completed != 3 || u.Total != 3 {
+		t.Fatalf("Expecting final progress update, got %+v", u)
+	}
+	<-done
+
+	// the final update is sent when the group stops early
+	ctx, cancel := context.WithCancel(StopIfCancelled(context.Background()))
+	managed = make(chan struct{})
+	m = &countedManager{Manager: ProgressChan(nil, ch), n: 4, done: managed}
+	go func() {
+		done <- WorkFor(ctx, NewSerial(), m, 10, func(ctx context.Context, index int) error {
+			if index == 3 {
+				cancel()
+			}
+			return nil
+		})
+	}()
+	<-managed
+	if u := <-ch; !u.Final || u.Completed != 4 || u.Total != 10 {
+		t.Fatalf("Expecting final progress update, got %+v", u)
+	}
+	<-done
+}
+
+func TestProgressTracker(t *testing.T) {
+
+	// each worker completes a millisecond after the previous worker
+	clock := &steppedClock{step: time.Millisecond}
+	ctx := WithClock(context.Background(), clock)
+
+	tracker := NewProgressTracker(5 * time.Millisecond)
+	err := WorkFor(ctx, clock, tracker.Manager(nil), 20, func(ctx context.Context, index int) error {
+		if index == 9 {
+			// sampled 6 workers in 5ms
+			s := tracker.Stats()
+			if s.Completed != 9 || s.Total != 20 || s.Throughput != 1200 || s.ETA != 11*time.Second/1200 {
+				return fmt.Errorf("unexpected progress: %+v", s)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := tracker.Stats()
+	if s.Completed != 20 || s.Total != 20 || s.ETA != 0 || s.Elapsed != 19*time.Millisecond {
+		t.Fatalf("Unexpected final progress: %+v", s)
+	}
+	// the throughput is averaged over samples of 1200, 1000 and 1000
+	if rate := 20 / 0.019; math.Abs(s.Rate-rate) > 1e-6 {
+		t.Fatalf("Expecting rate %f, got %f", rate, s.Rate)
+	}
+	if throughput := 0.3*1000 + 0.7*(0.3*1000+0.7*1200); math.Abs(s.Throughput-throughput) > 1e-6 {
+		t.Fatalf("Expecting throughput %f, got %f", throughput, s.Throughput)
+	}
+}
@@ -0,0 +1,90 @@
+package workgroup
+
+import (
+	"context"
+	"runtime"
+)
+
+// NewBoundedLimited returns an executer, like NewLimited, that will
+// execute functions on at most, n, goroutines simultaneously, and
+// that queues at most, capacity, functions waiting for a goroutine.
+// Execute returns once the function has been started or queued, and
+// when the queue is full, the function is rejected rather than queued,
+// and the work group of a worker that is rejected handles it
+// as if it completed with the error, ErrQueueFull, so that overload is
+// reported to the manager instead of hidden by unbounded queuing. The
+// function of a rejected worker is called on the calling goroutine with
+// a cancelled context. If capacity <= 0 then functions are rejected
+// whenever no goroutine is available.
+func NewBoundedLimited(n, capacity int) Executer {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &limited{
+		ch:       make(chan struct{}, n),
+		heavy:    make(chan struct{}, 1),
+		bounded:  true,
+		capacity: int64(capacity),
+	}
+}
+
+// queuedFunc is a function queued by a bounded executer.
+type queuedFunc struct {
+	ctx context.Context
+	f   func(context.Context)
+}
+
+// enqueue starts the function, f, if a slot is available and no other
+// function is queued, otherwise it queues the function, unless the
+// queue is full, in which case the function is rejected.
+func (l *limited) enqueue(ctx context.Context, f func(context.Context)) {
+	l.mutex.Lock()
+	if len(l.queue) == 0 {
+		select {
+		case l.ch <- struct{}{}:
+			l.mutex.Unlock()
+			l.start(ctx, f)
+			return
+		default:
+		}
+	}
+	if int64(len(l.queue)) >= l.capacity {
+		l.mutex.Unlock()
+		f(rejected(ctx, ErrQueueFull))
+		return
+	}
+	// a slot released from now on is followed by a dispatch
+	l.queue = append(l.queue, queuedFunc{ctx: ctx, f: f})
+	l.counts.enqueue()
+	l.mutex.Unlock()
+}
+
+// dispatch starts the queued functions while slots are available.
+func (l *limited) dispatch() {
+	for {
+		l.mutex.Lock()
+		if len(l.queue) == 0 {
+			l.mutex.Unlock()
+			return
+		}
+		select {
+		case l.ch <- struct{}{}:
+		default:
+			l.mutex.Unlock()
+			return
+		}
+		q := l.queue[0]
+		l.queue[0] = queuedFunc{}
+		l.queue = l.queue[1:]
+		l.mutex.Unlock()
+
+		l.counts.dequeue()
+		l.start(q.ctx, q.f)
+	}
+}
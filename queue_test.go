@@ -0,0 +1,101 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBoundedLimited(t *testing.T) {
+
+	var rejected int32
+	release := make(chan struct{})
+	m := Observe(CancelNeverFirstError(), Hooks{
+		OnError: func(idx int, err error) {
+			if errors.Is(err, ErrQueueFull) && atomic.AddInt32(&rejected, 1) == 4 {
+				close(release)
+			}
+		},
+	})
+
+	var ran int32
+	err := WorkFor(context.Background(), NewBoundedLimited(1, 0), m, 5, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&ran, 1)
+		<-release
+		return nil
+	})
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expecting error %v, got %v", ErrQueueFull, err)
+	}
+	if ran != 1 || rejected != 4 {
+		t.Fatalf("Expecting 1 worker run and 4 rejected, got %d and %d", ran, rejected)
+	}
+}
+
+func TestRejectWhenFullWork(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executers := []func() Executer{
+		func() Executer {
+			return NewBoundedLimited(1, 1)
+		},
+		func() Executer {
+			return NewPool(ctx, 1, WithQueueCapacity(1), RejectWhenFull())
+		},
+	}
+	for _, e := range executers {
+		// the workers are released once one of them is rejected,
+		// at most one is running and one is queued until then
+		release := make(chan struct{})
+		m := Observe(CancelNeverFirstError(), Hooks{
+			OnError: func(idx int, err error) {
+				if errors.Is(err, ErrQueueFull) && idx == 2 {
+					close(release)
+				}
+			},
+		})
+
+		var ran int32
+		err := WorkFor(ctx, e(), m, 4, func(ctx context.Context, index int) error {
+			atomic.AddInt32(&ran, 1)
+			<-release
+			return nil
+		})
+		if !errors.Is(err, ErrQueueFull) || ran < 1 || ran > 2 {
+			t.Fatalf("Expecting at most 2 workers run and %v, got %d: %v", ErrQueueFull, ran, err)
+		}
+	}
+}
+
+func TestPoolRejectWhenFull(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPool(ctx, 1, WithQueueCapacity(1), RejectWhenFull())
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	p.Execute(ctx, func(ctx context.Context) {
+		close(started)
+		<-block
+	})
+	<-started
+	go p.Execute(ctx, func(ctx context.Context) {})
+	for len(p.PendingTasks()) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	err := Work(ctx, p, nil, func(ctx context.Context) error {
+		t.Error("Rejected worker was invoked")
+		return nil
+	})
+	close(block)
+	if err != ErrQueueFull {
+		t.Fatalf("Expecting error %v, got %v", ErrQueueFull, err)
+	}
+}
@@ -0,0 +1,102 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidInterval is returned by Repeat if the interval is not positive.
+var ErrInvalidInterval = errors.New("workgroup: invalid interval")
+
+// Overlap is the policy of Repeat for when a run of the worker is
+// due while the previous run of the worker has not completed.
+type Overlap int
+
+const (
+	// OverlapSkip skips the run that is due.
+	OverlapSkip Overlap = iota
+
+	// OverlapQueue starts the run that is due when the previous run
+	// completes, runs that are due in the meantime are skipped.
+	OverlapQueue
+
+	// OverlapConcurrent starts the run that is due immediately.
+	OverlapConcurrent
+)
+
+// Repeat arranges for the worker, w, to be executed immediately and then
+// at every interval, d, until the context, ctx, is cancelled, and waits
+// for the runs to complete before returning. The runs are the workers of
+// a single work group, with the number of the run as the index, so the
+// manager decides whether a failed run stops the schedule, for example,
+// CancelOnFirstError stops it and CancelNeverFirstError does not. The
+// policy, overlap, determines whether a run that is due while a previous
+// run has not completed is skipped, queued, or started concurrently. The
+// time is measured by the clock of the context, see WithClock. If the
+// interval is not positive, then the worker is not executed, and the error
+// is ErrInvalidInterval. See documentation for Work() for details.
+func Repeat(ctx context.Context, e Executer, m Manager, d time.Duration, overlap Overlap, w Worker) error {
+	if d <= 0 {
+		return ErrInvalidInterval
+	}
+	grp := newGroup(ctx, e, m)
+	defer grp.close()
+
+	// the backlog is reported after each run is managed
+	wake := make(chan struct{}, 1)
+	backlog := grp.backlog
+	grp.backlog = func(n int) {
+		if backlog != nil {
+			backlog(n)
+		}
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+
+	clock := ClockFrom(grp.ctx)
+	var timer Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	due := true
+	for i := 0; grp.ctx.Err() == nil && !grp.stopped(); {
+		running := atomic.LoadInt64(&grp.running) > 0
+		if due && running && overlap == OverlapSkip {
+			due = false
+		}
+		if due && (!running || overlap == OverlapConcurrent) {
+			due = false
+			grp.execute(i, w)
+			i++
+		}
+		if grp.ctx.Err() != nil || grp.stopped() {
+			break
+		}
+		if timer == nil {
+			// the next run is timed once the run that is due has been
+			// submitted, so that a fake clock observes a settled schedule
+			timer = clock.NewTimer(d)
+		}
+
+		select {
+		case <-timer.C():
+			due = true
+			timer = nil
+			continue
+		case <-wake:
+			continue
+		case <-grp.ctx.Done():
+		case <-grp.stop:
+		}
+		break
+	}
+
+	return grp.wait()
+}
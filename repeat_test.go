@@ -0,0 +1,70 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRepeat(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	var runs int32
+	err := Repeat(context.Background(), nil, CancelOnFirstError(), time.Millisecond, OverlapSkip, func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) == 3 {
+			return failed
+		}
+		return nil
+	})
+	if err != failed || runs != 3 {
+		t.Fatalf("Expecting schedule stopped by the manager after 3 runs, got %d: %v", runs, err)
+	}
+}
+
+func TestRepeatOverlap(t *testing.T) {
+
+	repeat := func(overlap Overlap) (int32, int32) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		var runs, running, peak int32
+		Repeat(ctx, nil, CancelNeverFirstError(), 10*time.Millisecond, overlap, func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			if n > atomic.LoadInt32(&peak) {
+				atomic.StoreInt32(&peak, n)
+			}
+			atomic.AddInt32(&runs, 1)
+			time.Sleep(35 * time.Millisecond)
+			return nil
+		})
+		return runs, peak
+	}
+
+	if runs, peak := repeat(OverlapSkip); peak != 1 || runs > 4 {
+		t.Fatalf("Expecting runs to be skipped, got %d runs with peak %d", runs, peak)
+	}
+	if runs, peak := repeat(OverlapQueue); peak != 1 || runs < 3 || runs > 4 {
+		t.Fatalf("Expecting runs to be queued, got %d runs with peak %d", runs, peak)
+	}
+	if runs, peak := repeat(OverlapConcurrent); peak < 3 || runs < 6 {
+		t.Fatalf("Expecting concurrent runs, got %d runs with peak %d", runs, peak)
+	}
+}
+
+func TestRepeatInvalidInterval(t *testing.T) {
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		var runs int32
+		err := Repeat(context.Background(), nil, nil, d, OverlapConcurrent, func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+		if err != ErrInvalidInterval || runs != 0 {
+			t.Fatalf("Expecting no runs for interval %v, got %d: %v", d, runs, err)
+		}
+	}
+}
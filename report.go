@@ -0,0 +1,135 @@
+package workgroup
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+type reportKey struct{}
+
+// Report summarizes a single run of a work group, see WithReport.
+type Report struct {
+	// Err is the error of the work group provided by the manager.
+	Err error
+
+	// Workers is the number of workers executed by the group.
+	Workers int
+
+	// Failed is the number of workers that completed with an error.
+	Failed int
+
+	// Elapsed is the time from the start of the group
+	// until all of the workers have completed.
+	Elapsed time.Duration
+
+	// AllocBytes and AllocObjects are the bytes and the number
+	// of objects allocated on the heap while the group ran.
+	AllocBytes   uint64
+	AllocObjects uint64
+
+	// GCCycles is the number of completed GC cycles while the group ran.
+	GCCycles uint64
+
+	// Latency contains the percentiles of the time between
+	// the submission of each worker to the executer and the
+	// start of the worker, that is, the queueing delay.
+	Latency Latency
+}
+
+// WithReport returns a copy of the context, ctx, that configures the work
+// group started with it to call the function, fn, with a report of the
+// run when all the workers of the group have completed. The allocation
+// statistics of the report are sampled from the runtime at the start
+// and end of the run, so they include the allocations of any activity
+// that runs concurrently with the group, not only of its workers, and
+// are best compared between runs to spot regressions in the memory cost
+// of the workers. Work groups nested in the group are not reported.
+func WithReport(ctx context.Context, fn func(Report)) context.Context {
+	return context.WithValue(ctx, reportKey{}, fn)
+}
+
+// reportFrom returns a function that calls the functions
+// configured by WithReport and OnFinish, or nil if neither.
+func reportFrom(ctx context.Context) func(Report) {
+	fn, _ := ctx.Value(reportKey{}).(func(Report))
+	finish, _ := ctx.Value(finishKey{}).(func(Report))
+	if finish == nil {
+		return fn
+	}
+	return func(r Report) {
+		if fn != nil {
+			fn(r)
+		}
+		go finish(r)
+	}
+}
+
+var reportMetrics = []string{
+	"/gc/heap/allocs:bytes",
+	"/gc/heap/allocs:objects",
+	"/gc/cycles/total:gc-cycles",
+}
+
+// reporter collects the report of a single run of a work group.
+type reporter struct {
+	fn      func(Report)
+	clock   Clock
+	start   time.Time
+	samples []metrics.Sample
+	workers int64
+	failed  int64
+	latency latencyRecorder
+}
+
+func newReporter(fn func(Report), clock Clock) *reporter {
+	r := &reporter{
+		fn:      fn,
+		clock:   clock,
+		samples: readMetrics(),
+	}
+	r.start = clock.Now()
+	return r
+}
+
+// complete records the completion of a worker with the error, err.
+func (r *reporter) complete(err error) {
+	atomic.AddInt64(&r.workers, 1)
+	if err != nil {
+		atomic.AddInt64(&r.failed, 1)
+	}
+}
+
+// finish calls the report function with the
+// report of the run and the error of the group.
+func (r *reporter) finish(err error) {
+	elapsed := r.clock.Now().Sub(r.start)
+	end := readMetrics()
+	r.fn(Report{
+		Err:          err,
+		Workers:      int(atomic.LoadInt64(&r.workers)),
+		Failed:       int(atomic.LoadInt64(&r.failed)),
+		Elapsed:      elapsed,
+		AllocBytes:   metricDelta(r.samples[0], end[0]),
+		AllocObjects: metricDelta(r.samples[1], end[1]),
+		GCCycles:     metricDelta(r.samples[2], end[2]),
+		Latency:      r.latency.percentiles(),
+	})
+}
+
+func readMetrics() []metrics.Sample {
+	samples := make([]metrics.Sample, len(reportMetrics))
+	for i, name := range reportMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	return samples
+}
+
+func metricDelta(start, end metrics.Sample) uint64 {
+	if start.Value.Kind() != metrics.KindUint64 || end.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return end.Value.Uint64() - start.Value.Uint64()
+}
@@ -0,0 +1,47 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+var (
+	reportMutex sync.Mutex
+	reportSink  [][]byte
+)
+
+func TestWorkReport(t *testing.T) {
+
+	var report Report
+	ctx := WithReport(context.Background(), func(r Report) {
+		report = r
+	})
+
+	WorkFor(ctx, nil, CancelNeverFirstError(), 10, func(ctx context.Context, index int) error {
+		if reportFrom(ctx) != nil {
+			return errors.New("nested group inherited the report function")
+		}
+		buf := make([]byte, 1<<20)
+		buf[0] = byte(index)
+		reportMutex.Lock()
+		reportSink = append(reportSink, buf)
+		reportMutex.Unlock()
+		if index%2 == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	reportSink = nil
+
+	if report.Workers != 10 || report.Failed != 5 {
+		t.Fatalf("Expecting 10 workers with 5 failed, got %d with %d failed", report.Workers, report.Failed)
+	}
+	if report.AllocBytes < 10<<20 {
+		t.Fatalf("Expecting at least 10MiB allocated, got %d", report.AllocBytes)
+	}
+	if report.Elapsed <= 0 {
+		t.Fatalf("Expecting elapsed time, got %s", report.Elapsed)
+	}
+}
@@ -0,0 +1,114 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type retryBudgetKey struct{}
+
+type attemptKey struct{}
+
+// attempted records a retry of the worker with
+// the context, ctx, if its attempts are counted.
+func attempted(ctx context.Context) {
+	if n, ok := ctx.Value(attemptKey{}).(*int32); ok {
+		atomic.AddInt32(n, 1)
+	}
+}
+
+// RetryBudget limits the retries of the workers of work groups, so that
+// a systemic outage does not turn into a storm of retries, see Retry.
+type RetryBudget struct {
+	mutex    sync.Mutex
+	max      int
+	ratio    float64
+	attempts int
+	retries  int
+}
+
+// NewRetryBudget initializes a new retry budget that permits at most max
+// retries in total, and at most ratio retries for each first attempt, for
+// example, a ratio of 0.1 permits one retry for every ten workers. If max
+// <= 0 or ratio <= 0, then the respective limit is not applied.
+func NewRetryBudget(max int, ratio float64) *RetryBudget {
+	return &RetryBudget{max: max, ratio: ratio}
+}
+
+// WithRetryBudget returns a copy of the context, ctx, that configures the
+// work groups started with it, and all work groups nested within them, to
+// share the retry budget, b, between the workers wrapped by Retry.
+func WithRetryBudget(ctx context.Context, b *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, b)
+}
+
+// attempt records a first attempt.
+func (b *RetryBudget) attempt() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.attempts++
+}
+
+// retry reports whether a retry is permitted and, if so, consumes it.
+func (b *RetryBudget) retry() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.max > 0 && b.retries >= b.max {
+		return false
+	}
+	if b.ratio > 0 && float64(b.retries+1) > b.ratio*float64(b.attempts) {
+		return false
+	}
+	b.retries++
+	return true
+}
+
+// Retries returns the number of retries consumed from the budget.
+func (b *RetryBudget) Retries() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.retries
+}
+
+// Retry returns a worker that calls the worker, w, and retries it when it
+// completes with an error, up to attempts calls in total, until it succeeds
+// or its context is done. The delay before the first retry is backoff, and
+// the delay is doubled for each further retry. If the context is configured
+// by WithRetryBudget, then a retry is only made if the budget permits it.
+// The error of the last call is returned. The time is measured by the clock
+// of the context, see WithClock.
+func Retry(attempts int, backoff time.Duration, w Worker) Worker {
+	return RetryIf(nil, attempts, backoff, w)
+}
+
+// RetryIf returns a worker like Retry, except that the worker, w,
+// is only retried when its error is classified as SeverityRetryable
+// by the function, classify. If classify is nil, then every error
+// is retried, which is the same as Retry.
+func RetryIf(classify func(error) Severity, attempts int, backoff time.Duration, w Worker) Worker {
+	return func(ctx context.Context) error {
+		budget, _ := ctx.Value(retryBudgetKey{}).(*RetryBudget)
+		if budget != nil {
+			budget.attempt()
+		}
+
+		err := w(ctx)
+		for i := 1; i < attempts && err != nil; i++ {
+			if classify != nil && classify(err) != SeverityRetryable {
+				break
+			}
+			if ctx.Err() != nil || (budget != nil && !budget.retry()) {
+				break
+			}
+			if sleep(ctx, ClockFrom(ctx), backoff) != nil {
+				break
+			}
+			backoff *= 2
+			attempted(ctx)
+			err = w(ctx)
+		}
+		return err
+	}
+}
@@ -0,0 +1,74 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	var calls int32
+	err := Work(context.Background(), nil, nil, Retry(3, time.Millisecond, func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return failed
+		}
+		return nil
+	}))
+	if err != nil || calls != 3 {
+		t.Fatalf("Expecting success after 3 calls, got %d: %v", calls, err)
+	}
+
+	// a systemic outage exhausts the budget
+	budget := NewRetryBudget(5, 0)
+	calls = 0
+	ctx := WithRetryBudget(context.Background(), budget)
+	err = WorkFor(ctx, nil, CancelNeverFirstError(), 10, func(ctx context.Context, index int) error {
+		return Retry(3, time.Millisecond, func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return failed
+		})(ctx)
+	})
+	if err != failed || calls != 15 || budget.Retries() != 5 {
+		t.Fatalf("Expecting 15 calls with 5 retries, got %d and %d: %v", calls, budget.Retries(), err)
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+
+	var calls int32
+	worker := func(err error) Worker {
+		return RetryIf(classifyTest, 3, time.Millisecond, func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return err
+		})
+	}
+
+	if err := Work(context.Background(), nil, nil, worker(errInvalid)); err != errInvalid || calls != 1 {
+		t.Fatalf("Expecting 1 call without retry, got %d: %v", calls, err)
+	}
+	calls = 0
+	if err := Work(context.Background(), nil, nil, worker(errTransient)); err != errTransient || calls != 3 {
+		t.Fatalf("Expecting 3 calls with retry, got %d: %v", calls, err)
+	}
+}
+
+func TestRetryBudgetRatio(t *testing.T) {
+
+	b := NewRetryBudget(0, 0.5)
+	for i := 0; i < 4; i++ {
+		b.attempt()
+	}
+	for i := 0; i < 2; i++ {
+		if !b.retry() {
+			t.Fatalf("Expecting retry %d permitted", i)
+		}
+	}
+	if b.retry() {
+		t.Fatalf("Expecting retry denied by ratio")
+	}
+}
@@ -0,0 +1,75 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Runner runs work groups with the same executer and manager repeatedly,
+// and reuses the internal state of the work groups between runs, which
+// reduces the allocations of services that start thousands of small work
+// groups per second. The contexts of the workers, and the Scope of a work
+// group, must not be used after the work group completes, since the state
+// is then reused. A Runner is safe for concurrent use.
+type Runner struct {
+	e    Executer
+	m    func() Manager
+	pool sync.Pool
+	jobs sync.Pool
+}
+
+// NewRunner initializes a new runner of work groups with the executer, e,
+// and a manager provided by the function, m, for each work group, since a
+// manager holds the state of a single work group. If e or m is nil, then
+// the executer or manager is chosen as for Work.
+func NewRunner(e Executer, m func() Manager) *Runner {
+	return &Runner{e: e, m: m}
+}
+
+// Work is the same as the Work function with the executer
+// and a manager of the runner. See documentation for Work().
+func (r *Runner) Work(ctx context.Context, g ...Worker) error {
+	grp := r.group(ctx)
+	defer r.release(grp)
+	defer grp.close()
+	return grp.work(g)
+}
+
+// WorkFor is the same as the WorkFor function with the executer
+// and a manager of the runner. See documentation for WorkFor().
+func (r *Runner) WorkFor(ctx context.Context, n int, w IdxWorker) error {
+	grp := r.group(ctx)
+	defer r.release(grp)
+	defer grp.close()
+	return grp.workFor(n, w)
+}
+
+// group returns a new or reused group for the context, ctx.
+func (r *Runner) group(ctx context.Context) *group {
+	var m Manager
+	if r.m != nil {
+		m = r.m()
+	}
+	g, _ := r.pool.Get().(*group)
+	if g == nil {
+		g = new(group)
+	}
+	g.init(ctx, r.e, m)
+	g.jobs = &r.jobs
+	return g
+}
+
+// release releases the group, g, to be reused.
+func (r *Runner) release(g *group) {
+	if g.stall != nil {
+		// the stall monitor of the group may still be running
+		return
+	}
+	if atomic.LoadInt32(&g.listeners) != 0 {
+		// the functions registered by OnCancel may still be waiting
+		return
+	}
+	*g = group{}
+	r.pool.Put(g)
+}
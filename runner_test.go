@@ -0,0 +1,93 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunner(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	r := NewRunner(NewLimited(4), func() Manager {
+		return CancelOnFirstError()
+	})
+	for i := 0; i < 100; i++ {
+		var count int32
+		err := r.WorkFor(context.Background(), 10, func(ctx context.Context, index int) error {
+			atomic.AddInt32(&count, 1)
+			if index == i%10 && i%2 == 1 {
+				return failed
+			}
+			return nil
+		})
+		if (i%2 == 1) != (err == failed) || count != 10 {
+			t.Fatalf("Unexpected result of run %d, got %d workers: %v", i, count, err)
+		}
+	}
+
+	err := r.Work(context.Background(), func(ctx context.Context) error {
+		return NewRunner(nil, nil).Work(ctx, func(ctx context.Context) error {
+			return failed
+		})
+	})
+	if err != failed {
+		t.Fatalf("Expecting error of nested runner: %v", err)
+	}
+}
+
+func TestRunnerOnCancel(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	r := NewRunner(nil, func() Manager {
+		return CancelOnFirstError()
+	})
+	for i := 0; i < 100; i++ {
+		// The function registered by the first worker of a failed run
+		// is called before that worker completes, the function of a
+		// run that succeeds is not called.
+		fail := i%2 == 1
+		causes := make(chan error, 1)
+		err := r.Work(context.Background(), func(ctx context.Context) error {
+			CurrentGroup(ctx).OnCancel(func(cause error) {
+				causes <- cause
+			})
+			if fail {
+				if cause := <-causes; cause != failed {
+					t.Errorf("Expecting cause %v of run %d, got %v", failed, i, cause)
+				}
+			}
+			return nil
+		}, func(ctx context.Context) error {
+			if fail {
+				return failed
+			}
+			return nil
+		})
+		if fail != (err == failed) {
+			t.Fatalf("Unexpected result of run %d: %v", i, err)
+		}
+	}
+}
+
+func BenchmarkWork(b *testing.B) {
+	w := func(ctx context.Context) error { return nil }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Work(context.Background(), NewSerial(), CancelNeverFirstError(), w, w)
+	}
+}
+
+func BenchmarkRunnerWork(b *testing.B) {
+	w := func(ctx context.Context) error { return nil }
+	r := NewRunner(NewSerial(), func() Manager {
+		return CancelNeverFirstError()
+	})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Work(context.Background(), w, w)
+	}
+}
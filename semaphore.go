@@ -0,0 +1,99 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// semaphore is a weighted semaphore that
+// admits waiters in first-in first-out order.
+type semaphore struct {
+	mutex   sync.Mutex
+	size    int
+	cur     int
+	waiters []semaphoreWaiter
+}
+
+type semaphoreWaiter struct {
+	n     int
+	ready chan struct{}
+}
+
+func newSemaphore(size int) *semaphore {
+	return &semaphore{size: size}
+}
+
+// acquire blocks until n units are available.
+func (s *semaphore) acquire(n int) {
+	s.acquireContext(context.Background(), n)
+}
+
+// acquireContext blocks until n units are available, or returns
+// the error of the context, ctx, if it is done first.
+func (s *semaphore) acquireContext(ctx context.Context, n int) error {
+	s.mutex.Lock()
+	if len(s.waiters) == 0 && s.cur+n <= s.size {
+		s.cur += n
+		s.mutex.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, semaphoreWaiter{n: n, ready: ready})
+	s.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	select {
+	case <-ready:
+		// the units were admitted concurrently
+		s.cur -= n
+	default:
+		for i, w := range s.waiters {
+			if w.ready == ready {
+				s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+	s.notify()
+	return ctx.Err()
+}
+
+// release returns n units to the semaphore.
+func (s *semaphore) release(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cur -= n
+	s.notify()
+}
+
+// notify admits the waiters that fit, the mutex must be held.
+func (s *semaphore) notify() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.cur+w.n > s.size {
+			return
+		}
+		s.cur += w.n
+		close(w.ready)
+		s.waiters[0] = semaphoreWaiter{}
+		s.waiters = s.waiters[1:]
+	}
+}
+
+// resize changes the number of units of the semaphore.
+func (s *semaphore) resize(size int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.size = size
+	s.notify()
+}
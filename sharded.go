@@ -0,0 +1,151 @@
+package workgroup
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// shardCapacity is the number of functions that
+// can be queued in each shard of a sharded pool.
+const shardCapacity = 128
+
+type shardedTask struct {
+	ctx context.Context
+	f   func(context.Context)
+}
+
+// ShardedPool is an executer that executes functions on a fixed number
+// of goroutines, like Pool, but spreads the queued functions over a
+// number of shards, so that dispatch is not limited by a single queue.
+// Each goroutine serves its own shard and steals queued functions from
+// the other shards when its shard is empty. Functions are not started
+// in the order they are submitted.
+type ShardedPool struct {
+	mutex  sync.RWMutex
+	shards []chan shardedTask
+	wake   chan struct{}
+	closed chan struct{}
+	next   uint32
+}
+
+// NewShardedPool initializes a new sharded pool executer with n
+// goroutines and the given number of shards. If n <= 0 then the value
+// provided by DefaultLimit is used. If shards <= 0 then the value
+// provided by runtime.GOMAXPROCS is used, and the number of shards is
+// at most n. The pool executes functions in the calling goroutine once
+// the context, ctx, is done and the queued functions have been started.
+// If the context is nil, or is never done, then the pool is never closed.
+func NewShardedPool(ctx context.Context, n, shards int) *ShardedPool {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	if shards > n {
+		shards = n
+	}
+
+	p := &ShardedPool{
+		shards: make([]chan shardedTask, shards),
+		wake:   make(chan struct{}, n),
+		closed: make(chan struct{}),
+	}
+	for i := range p.shards {
+		p.shards[i] = make(chan shardedTask, shardCapacity)
+	}
+	for i := 0; i < n; i++ {
+		go p.run(i % shards)
+	}
+
+	if ctx != nil && ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			close(p.closed)
+		}()
+	}
+
+	return p
+}
+
+// Execute queues the function, f, on one of the shards of the pool,
+// and blocks while all of the shards are full. If the pool is closed
+// then the function is executed in the calling goroutine.
+func (p *ShardedPool) Execute(ctx context.Context, f func(context.Context)) {
+	p.mutex.RLock()
+	select {
+	case <-p.closed:
+		p.mutex.RUnlock()
+		f(ctx)
+		return
+	default:
+	}
+
+	t := shardedTask{ctx: ctx, f: f}
+	i := int(atomic.AddUint32(&p.next, 1) % uint32(len(p.shards)))
+	if !p.offer(i, t) {
+		p.shards[i] <- t
+	}
+	p.mutex.RUnlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// offer attempts to queue the task, t, on the shard, i,
+// or on the next shard with capacity, without blocking.
+func (p *ShardedPool) offer(i int, t shardedTask) bool {
+	for j := 0; j < len(p.shards); j++ {
+		select {
+		case p.shards[(i+j)%len(p.shards)] <- t:
+			return true
+		default:
+		}
+	}
+	return false
+}
+
+// steal returns a task queued on the shard, home,
+// or on any of the other shards, without blocking.
+func (p *ShardedPool) steal(home int) (shardedTask, bool) {
+	for j := 0; j < len(p.shards); j++ {
+		select {
+		case t := <-p.shards[(home+j)%len(p.shards)]:
+			return t, true
+		default:
+		}
+	}
+	return shardedTask{}, false
+}
+
+func (p *ShardedPool) run(home int) {
+	for {
+		if t, ok := p.steal(home); ok {
+			t.f(t.ctx)
+			continue
+		}
+
+		select {
+		case t := <-p.shards[home]:
+			t.f(t.ctx)
+		case <-p.wake:
+		case <-p.closed:
+			for {
+				t, ok := p.steal(home)
+				if !ok {
+					return
+				}
+				t.f(t.ctx)
+			}
+		}
+	}
+}
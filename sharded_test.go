@@ -0,0 +1,82 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestShardedPool(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewShardedPool(ctx, 4, 2)
+
+	var running, max int32
+	var count int32
+	err := WorkFor(ctx, p, nil, 1000, func(ctx context.Context, index int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 1000 {
+		t.Fatalf("Expecting 1000 workers executed, got %d", count)
+	}
+	if max > 4 {
+		t.Fatalf("Expecting at most 4 concurrent workers, got %d", max)
+	}
+
+	cancel()
+	err = WorkFor(context.Background(), p, nil, 10, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	if err != nil || count != 1010 {
+		t.Fatalf("Expecting workers executed after close, got %d: %v", count, err)
+	}
+}
+
+func benchmarkDispatch(b *testing.B, e Executer) {
+	var count int64
+	WorkFor(context.Background(), e, CancelNeverFirstError(), b.N, func(ctx context.Context, index int) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+}
+
+func BenchmarkPoolDispatch(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	benchmarkDispatch(b, NewPool(ctx, 0))
+}
+
+func BenchmarkShardedPoolDispatch(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	benchmarkDispatch(b, NewShardedPool(ctx, 0, 0))
+}
+
+func TestShardedPoolNilContext(t *testing.T) {
+
+	p := NewShardedPool(nil, 2, 2)
+
+	var count int32
+	WorkFor(context.Background(), p, nil, 10, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	if count != 10 {
+		t.Fatalf("Expecting 10 workers executed, got %d", count)
+	}
+}
@@ -0,0 +1,113 @@
+package workgroup
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ShuffleOption configures optional behavior of the shuffled executer.
+type ShuffleOption func(*ShuffledExecuter)
+
+// WithShuffleDelay configures the shuffled executer to delay
+// each function by a random duration of up to d before it
+// is called, to further perturb the scheduling of workers.
+func WithShuffleDelay(d time.Duration) ShuffleOption {
+	return func(s *ShuffledExecuter) {
+		s.delay = d
+	}
+}
+
+type shuffledTask struct {
+	ctx context.Context
+	f   func(context.Context)
+}
+
+// ShuffledExecuter is an executer for testing that dispatches
+// functions to an inner executer in a random order, see NewShuffled.
+type ShuffledExecuter struct {
+	inner Executer
+	seed  int64
+	delay time.Duration
+
+	mutex   sync.Mutex
+	rand    *rand.Rand
+	pending []shuffledTask
+	running bool
+}
+
+// NewShuffled returns an executer that randomizes the order that the
+// functions are dispatched to the executer, inner, to shake out bugs
+// that depend on the order workers are executed. Functions are held
+// until they are dispatched, so Execute does not block. The random
+// order is determined by the seed, if the seed is 0 then a seed is
+// chosen from the current time. Log the seed of a failing test and
+// use it to reproduce the order, although concurrent submissions
+// can still vary the order between runs. If executer, inner, is not
+// provided then DefaultExecuter is called to obtain the default.
+func NewShuffled(seed int64, inner Executer, opts ...ShuffleOption) *ShuffledExecuter {
+	if inner == nil {
+		inner = DefaultExecuter()
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := &ShuffledExecuter{
+		inner: inner,
+		seed:  seed,
+		rand:  rand.New(rand.NewSource(seed)),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Seed returns the seed that determines the random order.
+func (s *ShuffledExecuter) Seed() int64 {
+	return s.seed
+}
+
+func (s *ShuffledExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = append(s.pending, shuffledTask{ctx: ctx, f: f})
+	if !s.running {
+		s.running = true
+		go s.dispatch()
+	}
+}
+
+// dispatch dispatches the pending functions in a random order
+// to the inner executer, until there are no pending functions.
+func (s *ShuffledExecuter) dispatch() {
+	for {
+		s.mutex.Lock()
+		n := len(s.pending)
+		if n == 0 {
+			s.running = false
+			s.mutex.Unlock()
+			return
+		}
+		i := s.rand.Intn(n)
+		t := s.pending[i]
+		s.pending[i] = s.pending[n-1]
+		s.pending[n-1] = shuffledTask{}
+		s.pending = s.pending[:n-1]
+
+		var delay time.Duration
+		if s.delay > 0 {
+			delay = time.Duration(s.rand.Int63n(int64(s.delay)))
+		}
+		s.mutex.Unlock()
+
+		s.inner.Execute(t.ctx, func(ctx context.Context) {
+			if delay > 0 {
+				sleep(ctx, ClockFrom(ctx), delay)
+			}
+			t.f(ctx)
+		})
+	}
+}
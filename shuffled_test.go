@@ -0,0 +1,40 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShuffledWorkFor(t *testing.T) {
+
+	e := NewShuffled(0, NewLimited(1), WithShuffleDelay(10*time.Microsecond))
+	t.Logf("Shuffle seed: %d", e.Seed())
+
+	var mutex sync.Mutex
+	var order []int
+	WorkFor(context.Background(), e, nil, 100, func(ctx context.Context, index int) error {
+		mutex.Lock()
+		order = append(order, index)
+		first := len(order) == 1
+		mutex.Unlock()
+
+		// Hold the executer until the remaining workers are pending.
+		if first {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return nil
+	})
+
+	if len(order) != 100 {
+		t.Fatalf("Expecting 100 workers executed, got %d", len(order))
+	}
+	sorted := true
+	for i := 1; i < len(order); i++ {
+		sorted = sorted && order[i-1] < order[i]
+	}
+	if sorted {
+		t.Fatalf("Workers executed in submission order: %v", order)
+	}
+}
@@ -0,0 +1,84 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUntracked is matched by the error of Shutdown, using errors.Is, if
+// the running workers of the work group are not tracked, see WithScope.
+var ErrUntracked = errors.New("workgroup: running workers are not tracked")
+
+// Shutdown shuts down the work group gracefully. The group stops starting
+// workers, the manager handles the workers that have not started as if
+// they completed with the error, context.Canceled, and Shutdown waits for
+// the running workers to complete. If the context, ctx, is done first,
+// then the context of the group is cancelled and Shutdown returns the
+// indexes of the workers that were still running, in order, with the
+// error of ctx. The indexes are known for the work groups whose running
+// workers are tracked, see Dump, for other work groups the error matches
+// both the error of ctx and ErrUntracked, using errors.Is. The running
+// workers of a group that shares its context, see WithSharedContext, are
+// not cancelled. Shutdown can be used to implement a termination grace
+// period.
+func (s *Scope) Shutdown(ctx context.Context) (abandoned []int, err error) {
+	g := s.g
+	select {
+	case <-g.shutdown():
+		return nil, nil
+	case <-ctx.Done():
+	}
+
+	g.cancel()
+	if g.inflight == nil {
+		return nil, &untrackedError{err: ctx.Err()}
+	}
+	for _, info := range g.inflight.dump() {
+		abandoned = append(abandoned, info.Index)
+	}
+	return abandoned, ctx.Err()
+}
+
+// untrackedError is the error of a shutdown of a work group
+// that does not track its running workers.
+type untrackedError struct {
+	err error
+}
+
+func (e *untrackedError) Error() string {
+	return ErrUntracked.Error() + ": " + e.err.Error()
+}
+
+func (e *untrackedError) Is(target error) bool {
+	return target == ErrUntracked
+}
+
+func (e *untrackedError) Unwrap() error {
+	return e.err
+}
+
+// shutdown stops the group from starting workers and returns
+// a channel that is closed once the workers have completed.
+func (g *group) shutdown() <-chan struct{} {
+	h := g.handle
+	if h == nil {
+		g.stopOnce.Do(func() { close(g.stop) })
+		return g.done
+	}
+	// workers are added to a handle with its lock held,
+	// so none is added once the group has been stopped
+	h.mutex.Lock()
+	g.stopOnce.Do(func() { close(g.stop) })
+	h.mutex.Unlock()
+	return h.Done()
+}
+
+// stopped reports whether the work group has been shut down.
+func (g *group) stopped() bool {
+	select {
+	case <-g.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+type interruptKey struct{}
+
+// ErrInterrupted is matched, using errors.Is, by the error of a work
+// group that failed after it was cancelled by a signal, see WithSignals.
+var ErrInterrupted = errors.New("workgroup: interrupted")
+
+// WithSignals returns a copy of the context, ctx, that is cancelled when
+// the process receives one of the signals, sigs, or when the returned stop
+// function is called, like signal.NotifyContext. If no signals are given,
+// then os.Interrupt and syscall.SIGTERM are used. If a work group started
+// with the context, or nested within it, fails after the context has been
+// cancelled by a signal, then its error is annotated so that it matches
+// ErrInterrupted, using errors.Is, as well as the original error. The stop
+// function should be called to stop the notification of the signals.
+func WithSignals(ctx context.Context, sigs ...os.Signal) (context.Context, context.CancelFunc) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	i := &interrupt{}
+	ctx, cancel := context.WithCancel(ctx)
+	ctx = context.WithValue(ctx, interruptKey{}, i)
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	go func() {
+		defer signal.Stop(ch)
+		select {
+		case sig := <-ch:
+			i.set(sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// interrupt records the signal that cancelled a context.
+type interrupt struct {
+	mutex sync.Mutex
+	sig   os.Signal
+}
+
+func (i *interrupt) set(sig os.Signal) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.sig = sig
+}
+
+func (i *interrupt) signal() os.Signal {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.sig
+}
+
+// interruptError is the error of a work group cancelled by a signal.
+type interruptError struct {
+	sig os.Signal
+	err error
+}
+
+func (e *interruptError) Error() string {
+	return "workgroup: interrupted by " + e.sig.String() + ": " + e.err.Error()
+}
+
+func (e *interruptError) Is(target error) bool {
+	return target == ErrInterrupted
+}
+
+func (e *interruptError) Unwrap() error {
+	return e.err
+}
+
+// interrupted annotates the error, err, of a work group with the
+// context, ctx, if the context has been cancelled by a signal.
+func interrupted(ctx context.Context, err error) error {
+	i, ok := ctx.Value(interruptKey{}).(*interrupt)
+	if !ok || errors.Is(err, ErrInterrupted) {
+		return err
+	}
+	if sig := i.signal(); sig != nil {
+		return &interruptError{sig: sig, err: err}
+	}
+	return err
+}
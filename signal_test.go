@@ -0,0 +1,36 @@
+//go:build !windows
+
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestWithSignals(t *testing.T) {
+
+	ctx, stop := WithSignals(context.Background(), syscall.SIGUSR1)
+	defer stop()
+
+	err := WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+		if index == 0 {
+			syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expecting interrupted error, got %v", err)
+	}
+
+	ctx, stop = WithSignals(context.Background(), syscall.SIGUSR1)
+	stop()
+	err = Work(ctx, nil, nil, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expecting stopped context not to be interrupted, got %v", err)
+	}
+}
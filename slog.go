@@ -0,0 +1,104 @@
+//go:build go1.21
+
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// WithLogger returns a copy of the context, ctx, that configures the work
+// groups started with it, and all work groups nested within them, to log
+// the start and finish of each worker at the debug level, the errors of
+// workers at the warn level, panics at the error level, and the completion
+// of each group at the info level, or the error level if it failed, to the
+// logger. Records below the level, min, are not emitted, so that huge
+// groups can be kept quiet. The records have the attributes "group", the
+// name of the group, "index" and "worker", the index and name of the
+// worker, "duration" and "error", see WithGroupName and Named.
+func WithLogger(ctx context.Context, logger *slog.Logger, min slog.Leveler) context.Context {
+	if min == nil {
+		min = slog.LevelInfo
+	}
+	return context.WithValue(ctx, loggerKey{}, &slogLogger{logger: logger, min: min})
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+	min    slog.Leveler
+}
+
+func (l *slogLogger) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if level < l.min.Level() || !l.logger.Enabled(ctx, level) {
+		return
+	}
+	if g, ok := ctx.Value(groupKey{}).(*group); ok && g.name != "" {
+		attrs = append(attrs, slog.String("group", g.name))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (l *slogLogger) workerAttrs(ctx context.Context, index int, attrs ...slog.Attr) []slog.Attr {
+	attrs = append(attrs, slog.Int("index", index))
+	if name := WorkerName(ctx); name != "" {
+		attrs = append(attrs, slog.String("worker", name))
+	}
+	return attrs
+}
+
+func (l *slogLogger) started(ctx context.Context, index int) {
+	if slog.LevelDebug < l.min.Level() {
+		return
+	}
+	l.log(ctx, slog.LevelDebug, "worker started", l.workerAttrs(ctx, index)...)
+}
+
+func (l *slogLogger) finished(ctx context.Context, index int, d time.Duration, err error, panicked bool) {
+	var perr *PanicError
+	switch {
+	case panicked || errors.As(err, &perr):
+		attrs := l.workerAttrs(ctx, index, slog.Duration("duration", d))
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		l.log(ctx, slog.LevelError, "worker panicked", attrs...)
+	case err != nil:
+		l.log(ctx, slog.LevelWarn, "worker failed", l.workerAttrs(ctx, index, slog.Duration("duration", d), slog.Any("error", err))...)
+	default:
+		if slog.LevelDebug < l.min.Level() {
+			return
+		}
+		l.log(ctx, slog.LevelDebug, "worker finished", l.workerAttrs(ctx, index, slog.Duration("duration", d))...)
+	}
+}
+
+func (l *slogLogger) completed(ctx context.Context, d time.Duration, err error) {
+	if err != nil {
+		l.log(ctx, slog.LevelError, "group failed", slog.Duration("duration", d), slog.Any("error", err))
+		return
+	}
+	l.log(ctx, slog.LevelInfo, "group completed", slog.Duration("duration", d))
+}
+
+// LogStalls returns a function, for WithStallMonitor, that logs the
+// stalls of work groups to the logger at the warn level. The records
+// have the attributes "group", the name of the group, "stalled",
+// "pending" and "queued", see StallInfo, and "running", the indices
+// of the running workers.
+func LogStalls(logger *slog.Logger) func(StallInfo) {
+	return func(info StallInfo) {
+		running := make([]int, len(info.Running))
+		for i, w := range info.Running {
+			running[i] = w.Index
+		}
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "group stalled",
+			slog.String("group", info.Group),
+			slog.Duration("stalled", info.Stalled),
+			slog.Int("pending", info.Pending),
+			slog.Int("queued", info.Queued),
+			slog.Any("running", running),
+		)
+	}
+}
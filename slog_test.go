@@ -0,0 +1,83 @@
+//go:build go1.21
+
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a buffer that can be written concurrently.
+type syncBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestWithLogger(t *testing.T) {
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	ctx := WithGroupName(context.Background(), "backfill")
+	ctx = WithLogger(ctx, logger, slog.LevelDebug)
+	WorkFor(ctx, nil, Recover(CancelNeverFirstError()), 3, func(ctx context.Context, index int) error {
+		switch index {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("bad input")
+		}
+		return nil
+	})
+
+	out := buf.buf.String()
+	for _, want := range []string{
+		`level=DEBUG msg="worker started" index=0 group=backfill`,
+		`level=DEBUG msg="worker finished"`,
+		`level=WARN msg="worker failed"`,
+		`level=ERROR msg="worker panicked"`,
+		`level=ERROR msg="group failed"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Expecting log to contain %q:\n%s", want, out)
+		}
+	}
+
+	buf.buf.Reset()
+	WorkFor(WithLogger(context.Background(), logger, slog.LevelInfo), nil, nil, 3, func(ctx context.Context, index int) error {
+		return nil
+	})
+	if out := buf.buf.String(); strings.Count(out, "\n") != 1 || !strings.Contains(out, `msg="group completed"`) {
+		t.Fatalf("Expecting only the completion of the group logged:\n%s", out)
+	}
+}
+
+func TestLogStalls(t *testing.T) {
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	LogStalls(logger)(StallInfo{
+		Group:   "backfill",
+		Pending: 2,
+		Running: []WorkerInfo{{Index: 3}, {Index: 5}},
+	})
+
+	out := buf.buf.String()
+	for _, want := range []string{"level=WARN", `msg="group stalled"`, "group=backfill", "pending=2", "running=\"[3 5]\""} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Expecting %s in log: %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type stallKey struct{}
+
+// StallInfo describes a work group that has made no progress, see
+// WithStallMonitor.
+type StallInfo struct {
+	// Group is the name of the work group, see WithGroupName.
+	Group string
+
+	// Stalled is the time since the work group last made progress.
+	Stalled time.Duration
+
+	// Pending is the number of workers that have been submitted
+	// to the executer and have not completed, whether or not they
+	// have been started.
+	Pending int
+
+	// Queued is the number of functions waiting to be executed by the
+	// executer of the group, if the executer is a StatsProvider.
+	Queued int
+
+	// Running describes the workers that have been started and
+	// have not completed, in order of index.
+	Running []WorkerInfo
+}
+
+type stallMonitor struct {
+	d       time.Duration
+	onStall func(StallInfo)
+}
+
+// WithStallMonitor returns a copy of the context, ctx, that configures the
+// work groups started with it, and all work groups nested within them, to
+// call the function, onStall, when a group with pending workers has made
+// no progress for the duration, d, that is, no worker has been started and
+// no worker has completed. The function is called on a new goroutine, at
+// most once for each stall, with the pending and running workers of the
+// group and the queue depth of its executer, so that nested groups that
+// deadlock on a shared limited executer do not hang silently. The time is
+// measured by the clock of the context, see WithClock.
+func WithStallMonitor(ctx context.Context, d time.Duration, onStall func(StallInfo)) context.Context {
+	return context.WithValue(ctx, stallKey{}, &stallMonitor{d: d, onStall: onStall})
+}
+
+func stallMonitorFrom(ctx context.Context) *stallMonitor {
+	m, _ := ctx.Value(stallKey{}).(*stallMonitor)
+	return m
+}
+
+// monitor watches the progress of the group, g, until it completes.
+func (m *stallMonitor) monitor(g *group) {
+	clock, done := ClockFrom(g.ctx), g.done
+	last := atomic.LoadInt64(&g.progress)
+	since := clock.Now()
+	reported := false
+	for {
+		timer := clock.NewTimer(m.d)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		now := clock.Now()
+		progress := atomic.LoadInt64(&g.progress)
+		pending := int(atomic.LoadInt64(&g.running))
+		if progress != last || pending == 0 {
+			last, since, reported = progress, now, false
+			continue
+		}
+		if reported {
+			continue
+		}
+		reported = true
+
+		info := StallInfo{
+			Group:   g.name,
+			Stalled: now.Sub(since),
+			Pending: pending,
+			Running: g.inflight.dump(),
+		}
+		for i := range info.Running {
+			info.Running[i].Name = g.workerName(info.Running[i].Index)
+			info.Running[i].Group = g.name
+		}
+		if s, ok := g.e.(StatsProvider); ok {
+			info.Queued = s.Stats().Queued
+		}
+		m.onStall(info)
+	}
+}
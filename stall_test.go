@@ -0,0 +1,39 @@
+package workgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStallMonitor(t *testing.T) {
+
+	stalls := make(chan StallInfo, 1)
+	ctx := WithGroupName(context.Background(), "stuck")
+	ctx = WithStallMonitor(ctx, 10*time.Millisecond, func(info StallInfo) {
+		stalls <- info
+	})
+
+	release := make(chan struct{})
+
+	var info StallInfo
+	err := WorkFor(ctx, NewLimited(2), nil, 3, func(ctx context.Context, index int) error {
+		if index == 0 {
+			info = <-stalls
+			close(release)
+			return nil
+		}
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	if info.Group != "stuck" || info.Pending != 3 || info.Queued != 1 || info.Stalled < 10*time.Millisecond {
+		t.Fatalf("Unexpected stall: %+v", info)
+	}
+	if len(info.Running) != 2 || info.Running[0].Index != 0 || info.Running[1].Index != 1 {
+		t.Fatalf("Expecting workers 0 and 1 running, got %+v", info.Running)
+	}
+}
@@ -0,0 +1,70 @@
+package workgroup
+
+import "sync/atomic"
+
+// Stats contains runtime statistics of an executer.
+type Stats struct {
+	// Active is the number of functions that are executing.
+	Active int
+
+	// Queued is the number of functions that are waiting to be executed.
+	Queued int
+
+	// Completed is the total number of functions that have completed.
+	Completed uint64
+
+	// Peak is the highest number of functions that executed concurrently.
+	Peak int
+
+	// Latency contains the percentiles of the time that
+	// the most recent tasks waited before they started,
+	// it is only provided by the pool executer.
+	Latency Latency
+}
+
+// StatsProvider is implemented by executers that provide runtime
+// statistics, such as the limited and the pool executers.
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// counters counts the functions of an executer.
+type counters struct {
+	active    int64
+	queued    int64
+	completed uint64
+	peak      int64
+}
+
+// enqueue counts a queued function and returns the number queued.
+func (c *counters) enqueue() int64 {
+	return atomic.AddInt64(&c.queued, 1)
+}
+
+func (c *counters) dequeue() {
+	atomic.AddInt64(&c.queued, -1)
+}
+
+func (c *counters) start() {
+	n := atomic.AddInt64(&c.active, 1)
+	for {
+		peak := atomic.LoadInt64(&c.peak)
+		if n <= peak || atomic.CompareAndSwapInt64(&c.peak, peak, n) {
+			return
+		}
+	}
+}
+
+func (c *counters) finish() {
+	atomic.AddInt64(&c.active, -1)
+	atomic.AddUint64(&c.completed, 1)
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Active:    int(atomic.LoadInt64(&c.active)),
+		Queued:    int(atomic.LoadInt64(&c.queued)),
+		Completed: atomic.LoadUint64(&c.completed),
+		Peak:      int(atomic.LoadInt64(&c.peak)),
+	}
+}
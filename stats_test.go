@@ -0,0 +1,46 @@
+package workgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExecuterStats(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executers := map[string]Executer{
+		"limited": NewLimited(3),
+		"pool":    NewPool(ctx, 3),
+	}
+
+	for name, e := range executers {
+		block := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- WorkFor(ctx, e, nil, 5, func(ctx context.Context, index int) error {
+				<-block
+				return nil
+			})
+		}()
+
+		s := e.(StatsProvider)
+		for s.Stats().Active < 3 || s.Stats().Queued < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		close(block)
+		<-done
+
+		// The executer counts a function as completed after
+		// the worker, so wait for the counts to settle.
+		for s.Stats().Completed < 5 {
+			time.Sleep(time.Millisecond)
+		}
+		stats := s.Stats()
+		if stats.Active != 0 || stats.Queued != 0 || stats.Completed != 5 || stats.Peak != 3 {
+			t.Fatalf("Unexpected %s executer stats: %+v", name, stats)
+		}
+	}
+}
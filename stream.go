@@ -0,0 +1,51 @@
+package workgroup
+
+import "context"
+
+// Result is the result of a worker, see WorkStream.
+type Result[T any] struct {
+	// Index is the index of the worker.
+	Index int
+
+	// Value is the value returned by the worker.
+	Value T
+
+	// Err is the error returned by the worker.
+	Err error
+}
+
+// WorkStream arranges for the function, fn, to be executed n times, like
+// WorkFor, and sends the result of each worker on the results channel as
+// the worker completes, so that the results can be processed while the
+// group runs. The channel is closed once all of the workers have completed,
+// and the returned function waits for the workers to complete and returns
+// the error of the group. The results channel must be drained, unless the
+// context, ctx, is cancelled, in which case pending results are discarded.
+// The results are sent in order of index if configured by OrderedResults.
+// See documention for Work() for details.
+func WorkStream[T any](ctx context.Context, e Executer, m Manager, n int, fn func(context.Context, int) (T, error)) (<-chan Result[T], func() error) {
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+	results := make(chan Result[T])
+	done := make(chan struct{})
+
+	var err error
+	go func() {
+		defer close(done)
+		defer close(results)
+
+		emit := newEmitter[Result[T]](ctx, results, 0)
+		err = WorkFor(ctx, e, m, n, func(wctx context.Context, index int) error {
+			v, err := fn(wctx, index)
+			emit.emit(index, Result[T]{Index: index, Value: v, Err: err}, true)
+			return err
+		})
+		emit.close()
+	}()
+
+	return results, func() error {
+		<-done
+		return err
+	}
+}
@@ -0,0 +1,56 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWorkStream(t *testing.T) {
+
+	results, wait := WorkStream(context.Background(), NewLimited(2), CancelNeverFirstError(), 5, func(ctx context.Context, index int) (int, error) {
+		if index == 3 {
+			return 0, fmt.Errorf("worker %d failed", index)
+		}
+		return index * index, nil
+	})
+
+	seen := make(map[int]bool)
+	for r := range results {
+		seen[r.Index] = true
+		if r.Index == 3 && r.Err == nil {
+			t.Fatalf("Expecting error of worker 3")
+		}
+		if r.Index != 3 && (r.Err != nil || r.Value != r.Index*r.Index) {
+			t.Fatalf("Unexpected result: %+v", r)
+		}
+	}
+	if len(seen) != 5 {
+		t.Fatalf("Expecting 5 results, got %d", len(seen))
+	}
+	if err := wait(); err == nil || err.Error() != "worker 3 failed" {
+		t.Fatalf("Expecting error of worker 3, got %v", err)
+	}
+}
+
+func TestWorkStreamOrdered(t *testing.T) {
+
+	ctx := OrderedResults(context.Background())
+	results, wait := WorkStream(ctx, nil, nil, 10, func(ctx context.Context, index int) (int, error) {
+		// later workers complete first
+		time.Sleep(time.Duration(10-index) * time.Millisecond)
+		return index, nil
+	})
+
+	next := 0
+	for r := range results {
+		if r.Index != next || r.Value != next {
+			t.Fatalf("Expecting result %d, got %+v", next, r)
+		}
+		next++
+	}
+	if err := wait(); err != nil || next != 10 {
+		t.Fatalf("Expecting 10 results, got %d: %v", next, err)
+	}
+}
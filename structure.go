@@ -0,0 +1,137 @@
+package workgroup
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type groupKey struct{}
+
+type jobKey struct{}
+
+type structureKey struct{}
+
+type scopeKey struct{}
+
+// Scope is a handle to the work group of a worker, see CurrentGroup.
+type Scope struct {
+	g *group
+}
+
+// CurrentGroup returns the work group of the worker with the
+// context, ctx, or nil if the context is not of a worker.
+func CurrentGroup(ctx context.Context) *Scope {
+	if g, ok := ctx.Value(groupKey{}).(*group); ok {
+		return &Scope{g: g}
+	}
+	return nil
+}
+
+// Parent returns the work group that encloses the
+// work group, or nil if the work group is not nested.
+func (s *Scope) Parent() *Scope {
+	if s.g.parent == nil {
+		return nil
+	}
+	return &Scope{g: s.g.parent}
+}
+
+// Depth returns the number of work groups that enclose the work group.
+func (s *Scope) Depth() int {
+	n := 0
+	for g := s.g.parent; g != nil; g = g.parent {
+		n++
+	}
+	return n
+}
+
+// WithScope returns a copy of the context, ctx, that configures the work
+// group started with it to call the function, fn, with the scope of the
+// group when it starts, and to track its running workers, see Dump. The
+// scope can be kept, for example, by a diagnostics endpoint, to ask a
+// work group that appears to hang which workers it is waiting on. Work
+// groups nested in the group do not call the function.
+func WithScope(ctx context.Context, fn func(*Scope)) context.Context {
+	return context.WithValue(ctx, scopeKey{}, fn)
+}
+
+// Dump returns the workers of the work group that are running, in order
+// of their index. The running workers are tracked for the work group of
+// a Handle, and for a work group started with a context configured by
+// WithScope or WithStallMonitor. Dump panics for other work groups, such
+// as the work group of CurrentGroup started without WithScope.
+func (s *Scope) Dump() []WorkerInfo {
+	if s.g.inflight == nil {
+		panic("workgroup: running workers are not tracked, see WithScope")
+	}
+	infos := s.g.inflight.dump()
+	for i := range infos {
+		infos[i].Name = s.g.workerName(infos[i].Index)
+		infos[i].Group = s.g.name
+	}
+	return infos
+}
+
+// WithStructureCheck returns a copy of the context, ctx, that enables
+// a runtime assertion for goroutines started by Go. If Go is called
+// with a context that is not of a running worker, for example, from a
+// goroutine that has escaped the work group of the worker, then Go
+// panics. The check is intended to catch unstructured concurrency
+// during development.
+func WithStructureCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, structureKey{}, true)
+}
+
+// Go starts the function, fn, on a new goroutine that is registered
+// with the work group of the worker with the context, ctx. The work
+// group does not complete until the goroutine has returned. If the
+// context is not of a running worker, or of a running goroutine started
+// by Go, for example, if the worker has returned while other workers of
+// the group are running, then the goroutine is not registered, or Go
+// panics if the context is configured by WithStructureCheck.
+func Go(ctx context.Context, fn func(context.Context)) {
+	j, _ := ctx.Value(jobKey{}).(*job)
+	if j == nil || !j.register() {
+		if check, _ := ctx.Value(structureKey{}).(bool); check {
+			panic("workgroup: goroutine started outside of a running worker")
+		}
+		go fn(ctx)
+		return
+	}
+
+	g := j.g
+	go func() {
+		defer g.wg.Done()
+		defer j.leave()
+		defer atomic.AddInt64(&g.running, -1)
+		fn(ctx)
+	}()
+}
+
+// register registers a goroutine with the group of the job and reports
+// whether the job, or a goroutine registered with it, is running, if not
+// then the goroutine is not registered. The group is added to while the
+// job is live, so that it cannot complete, or be reused, concurrently.
+func (j *job) register() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.live == 0 {
+		return false
+	}
+	j.live++
+	j.g.wg.Add(1)
+	atomic.AddInt64(&j.g.running, 1)
+	return true
+}
+
+// leave ends the job, or a goroutine registered with it, and releases
+// the job to be reused, see Runner, when the last of them has ended.
+func (j *job) leave() {
+	j.mutex.Lock()
+	j.live--
+	last, p := j.live == 0, j.g.jobs
+	j.mutex.Unlock()
+	if last && p != nil {
+		j.release(p)
+	}
+}
@@ -0,0 +1,248 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCurrentGroup(t *testing.T) {
+
+	if CurrentGroup(context.Background()) != nil {
+		t.Fatalf("Expecting no group for background context")
+	}
+
+	var depth int32 = -1
+	Work(context.Background(), nil, nil,
+		GroupFor(nil, nil, 1, func(ctx context.Context, index int) error {
+			s := CurrentGroup(ctx)
+			if s.Parent() == nil || s.Parent().Parent() != nil {
+				return context.Canceled
+			}
+			atomic.StoreInt32(&depth, int32(s.Depth()))
+			return nil
+		}),
+	)
+	if depth != 1 {
+		t.Fatalf("Expecting nested group depth of 1, got %d", depth)
+	}
+}
+
+func TestGo(t *testing.T) {
+
+	var done int32
+	Work(context.Background(), nil, nil, func(ctx context.Context) error {
+		Go(ctx, func(ctx context.Context) {
+			time.Sleep(10 * time.Millisecond)
+			atomic.StoreInt32(&done, 1)
+		})
+		return nil
+	})
+	if atomic.LoadInt32(&done) != 1 {
+		t.Fatalf("Work completed before the registered goroutine")
+	}
+}
+
+func TestGoStructureCheck(t *testing.T) {
+
+	var escaped context.Context
+	Work(WithStructureCheck(context.Background()), nil, nil, func(ctx context.Context) error {
+		escaped = ctx
+		return nil
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expecting panic for goroutine started outside of a worker")
+		}
+	}()
+	Go(escaped, func(ctx context.Context) {})
+}
+
+func TestGoEscapedWorker(t *testing.T) {
+
+	escaped := make(chan context.Context, 1)
+	started := make(chan bool, 1)
+	WorkFor(WithStructureCheck(context.Background()), nil, nil, 2,
+		func(ctx context.Context, index int) error {
+			if index == 0 {
+				escaped <- ctx
+				return nil
+			}
+			// the sibling is running while the returned worker's context is used
+			ctx0 := <-escaped
+			time.Sleep(10 * time.Millisecond)
+			func() {
+				defer func() { started <- recover() == nil }()
+				Go(ctx0, func(ctx context.Context) {})
+			}()
+			return nil
+		})
+	if <-started {
+		t.Fatalf("Expecting panic for goroutine started by a returned worker")
+	}
+}
+
+func TestGoNested(t *testing.T) {
+
+	var done int32
+	r := NewRunner(nil, nil)
+	for i := 0; i < 100; i++ {
+		r.WorkFor(context.Background(), 4, func(ctx context.Context, index int) error {
+			Go(ctx, func(ctx context.Context) {
+				Go(ctx, func(ctx context.Context) {
+					atomic.AddInt32(&done, 1)
+				})
+			})
+			return nil
+		})
+	}
+	if n := atomic.LoadInt32(&done); n != 400 {
+		t.Fatalf("Expecting 400 goroutines to complete with the groups, got %d", n)
+	}
+}
+
+func TestScopeDump(t *testing.T) {
+
+	scopes := make(chan *Scope, 1)
+	ctx := WithScope(context.Background(), func(s *Scope) {
+		scopes <- s
+	})
+
+	block := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WorkFor(ctx, nil, nil, 4, func(ctx context.Context, index int) error {
+			if index%2 == 1 {
+				<-block
+			}
+			return nil
+		})
+	}()
+
+	scope := <-scopes
+	for len(scope.Dump()) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	infos := scope.Dump()
+	if infos[0].Index != 1 || infos[1].Index != 3 || infos[0].Started.IsZero() {
+		t.Fatalf("Unexpected running workers: %+v", infos)
+	}
+
+	close(block)
+	<-done
+	if n := len(scope.Dump()); n != 0 {
+		t.Fatalf("Expecting no running workers, got %d", n)
+	}
+}
+
+func TestScopeDumpUntracked(t *testing.T) {
+
+	var recovered interface{}
+	Work(context.Background(), nil, nil, func(ctx context.Context) error {
+		defer func() { recovered = recover() }()
+		CurrentGroup(ctx).Dump()
+		return nil
+	})
+	if recovered == nil {
+		t.Fatalf("Expecting panic for a group that does not track its workers")
+	}
+}
+
+func TestScopeShutdown(t *testing.T) {
+
+	scopes := make(chan *Scope, 1)
+	ctx := WithScope(context.Background(), func(s *Scope) {
+		scopes <- s
+	})
+
+	started := make(chan int, 10)
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(ctx, NewLimited(2), CancelNeverFirstError(), 10, func(ctx context.Context, index int) error {
+			started <- index
+			if index == 1 {
+				// ignores the shutdown until the group is cancelled
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+	}()
+
+	scope := <-scopes
+	<-started
+	<-started
+
+	sctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	abandoned, err := scope.Shutdown(sctx)
+	if err != context.DeadlineExceeded || len(abandoned) != 1 || abandoned[0] != 1 {
+		t.Fatalf("Expecting worker 1 abandoned, got %v: %v", abandoned, err)
+	}
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Expecting group cancelled, got %v", err)
+	}
+	if n := len(started); n != 0 {
+		t.Fatalf("Expecting no workers started after shutdown, got %d", n)
+	}
+}
+
+func TestScopePause(t *testing.T) {
+
+	scopes := make(chan *Scope, 1)
+	ctx := WithScope(context.Background(), func(s *Scope) {
+		scopes <- s
+	})
+
+	var completed int32
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- WorkFor(ctx, NewLimited(2), nil, 10, func(ctx context.Context, index int) error {
+			<-release
+			atomic.AddInt32(&completed, 1)
+			return nil
+		})
+	}()
+
+	scope := <-scopes
+	scope.Pause()
+	if !scope.Paused() {
+		t.Fatalf("Expecting group paused")
+	}
+	close(release)
+
+	// Workers submitted before the pause may complete.
+	time.Sleep(20 * time.Millisecond)
+	n := atomic.LoadInt32(&completed)
+	time.Sleep(20 * time.Millisecond)
+	if m := atomic.LoadInt32(&completed); m != n || m == 10 {
+		t.Fatalf("Expecting no progress while paused, completed %d then %d", n, m)
+	}
+
+	scope.Resume()
+	if err := <-done; err != nil || completed != 10 {
+		t.Fatalf("Expecting 10 workers completed, got %d: %v", completed, err)
+	}
+}
+
+func TestScopeShutdownUntracked(t *testing.T) {
+
+	var abandoned []int
+	var err error
+	Work(context.Background(), nil, nil, func(ctx context.Context) error {
+		sctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		// the worker is running, so the group does not complete
+		abandoned, err = CurrentGroup(ctx).Shutdown(sctx)
+		return nil
+	})
+	if abandoned != nil || !errors.Is(err, ErrUntracked) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expecting untracked workers, got %v: %v", abandoned, err)
+	}
+}
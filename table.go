@@ -0,0 +1,40 @@
+package workgroup
+
+import (
+	"context"
+	"fmt"
+)
+
+// Labeler is implemented by table rows that provide
+// a label used to identify the row in errors.
+type Labeler interface {
+	Label() string
+}
+
+// WorkTable arranges for the function, fn, to be executed for each
+// of the rows, and waits for these workers to complete. The error
+// of a row is annotated as a WorkerError with the index of the row
+// and its label, which is provided by the Labeler interface or, if
+// not implemented, by the fmt.Stringer interface. This is convenient
+// for test harnesses and scripts that iterate over a table of
+// configurations. See documention for Work() for details.
+func WorkTable[T any](ctx context.Context, e Executer, m Manager, rows []T, fn func(context.Context, T) error) error {
+	return WorkFor(ctx, e, m, len(rows), func(ctx context.Context, i int) error {
+		row := rows[i]
+		if err := fn(ctx, row); err != nil {
+			return &WorkerError{Index: i, Name: rowLabel(row), Err: err}
+		}
+		return nil
+	})
+}
+
+func rowLabel(row interface{}) string {
+	switch r := row.(type) {
+	case Labeler:
+		return r.Label()
+	case fmt.Stringer:
+		return r.String()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,47 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tableRow struct {
+	region string
+	fail   bool
+}
+
+func (r tableRow) Label() string {
+	return r.region
+}
+
+func TestWorkTable(t *testing.T) {
+
+	rows := []tableRow{
+		{region: "us-east"},
+		{region: "us-west", fail: true},
+		{region: "eu-central"},
+	}
+
+	failed := errors.New("failed")
+	m := Accumulate(CancelNeverFirstError())
+
+	err := WorkTable(context.Background(), nil, m, rows,
+		func(ctx context.Context, row tableRow) error {
+			if row.fail {
+				return failed
+			}
+			return nil
+		},
+	)
+
+	if err == nil || err.Error() != "worker us-west[1]: failed" {
+		t.Fatalf("Work table error is not labeled: %v", err)
+	}
+	if !errors.Is(err, failed) {
+		t.Fatalf("Work table error does not unwrap to row error")
+	}
+	if m.ErrorAt(0) != nil || m.ErrorAt(1) == nil || m.ErrorAt(2) != nil {
+		t.Fatalf("Work table errors are not indexed by row")
+	}
+}
@@ -0,0 +1,24 @@
+package workgroup
+
+import "context"
+
+// Task is a function that performs work and returns a value
+type Task[T any] func(context.Context) (T, error)
+
+// WorkTasks arranges for the tasks to be executed, like Work, and returns
+// the values of the tasks in the order of the tasks, so that the caller
+// need not allocate a results slice and capture indices in closures. The
+// values are returned with the error of the work group, if any, so that
+// the values of the tasks that succeeded are available when the manager,
+// m, does not cancel, such as for CancelNeverFirstError or Accumulate.
+// The value of a task that returned an error is the value it returned.
+// See documention for Work() for details.
+func WorkTasks[T any](ctx context.Context, e Executer, m Manager, tasks ...Task[T]) ([]T, error) {
+	values := make([]T, len(tasks))
+	err := WorkFor(ctx, e, m, len(tasks), func(ctx context.Context, index int) error {
+		v, err := tasks[index](ctx)
+		values[index] = v
+		return err
+	})
+	return values, err
+}
@@ -0,0 +1,43 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWorkTasks(t *testing.T) {
+
+	tasks := make([]Task[int], 10)
+	for i := range tasks {
+		n := i
+		tasks[i] = func(ctx context.Context) (int, error) {
+			return n * n, nil
+		}
+	}
+	values, err := WorkTasks(context.Background(), NewUnlimited(), nil, tasks...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, v := range values {
+		if v != i*i {
+			t.Fatalf("Expecting value %d at index %d, got %d", i*i, i, v)
+		}
+	}
+
+	failed := errors.New("failed")
+	strs, err := WorkTasks(context.Background(), nil, CancelNeverFirstError(),
+		func(ctx context.Context) (string, error) {
+			return "a", nil
+		},
+		func(ctx context.Context) (string, error) {
+			return "", failed
+		},
+	)
+	if err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+	if len(strs) != 2 || strs[0] != "a" {
+		t.Fatalf("Expecting values of tasks that succeeded, got %v", strs)
+	}
+}
@@ -0,0 +1,24 @@
+package workgroup
+
+import "context"
+
+// Tee returns a worker that executes all of the workers with the same
+// context, in a work group nested in the group of the returned worker,
+// and returns the error of the nested group provided by DefaultManager.
+// For example, to write to a primary store and to an audit log. The
+// nested group uses the default executer, see Work() for details. Use
+// Group to provide a different executer or manager.
+func Tee(workers ...Worker) Worker {
+	return Group(nil, nil, workers...)
+}
+
+// TeeFor returns a function that calls all of the functions, fns, with
+// the same value, in a nested work group, see Tee. The result can be used
+// with WorkChanFor, WorkTable and the stages of a pipeline, for example.
+func TeeFor[T any](fns ...func(context.Context, T) error) func(context.Context, T) error {
+	return func(ctx context.Context, v T) error {
+		return WorkFor(ctx, nil, nil, len(fns), func(ctx context.Context, i int) error {
+			return fns[i](ctx, v)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+
+	var primary, audit int32
+	err := Tee(
+		func(ctx context.Context) error {
+			atomic.AddInt32(&primary, 1)
+			return nil
+		},
+		func(ctx context.Context) error {
+			atomic.AddInt32(&audit, 1)
+			return nil
+		},
+	)(context.Background())
+	if err != nil || primary != 1 || audit != 1 {
+		t.Fatalf("Expecting both workers executed: %v", err)
+	}
+
+	failed := errors.New("failed")
+	var sum int64
+	items := make(chan int, 10)
+	for i := 1; i <= 10; i++ {
+		items <- i
+	}
+	close(items)
+
+	err = WorkChanFor(context.Background(), nil, CancelNeverFirstError(), items, TeeFor(
+		func(ctx context.Context, v int) error {
+			atomic.AddInt64(&sum, int64(v))
+			return nil
+		},
+		func(ctx context.Context, v int) error {
+			if v == 5 {
+				return failed
+			}
+			return nil
+		},
+	))
+	if err != failed || sum != 55 {
+		t.Fatalf("Expecting sum of 55 and error %v, got %d: %v", failed, sum, err)
+	}
+}
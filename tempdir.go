@@ -0,0 +1,123 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+type tempKey struct{}
+
+type groupTempKey struct{}
+
+type workerTempKey struct{}
+
+// ErrNoTempDir is the error returned by TempDir and GroupTempDir when
+// the context is not configured by WithTempDir or is not of a worker.
+var ErrNoTempDir = errors.New("workgroup: no temporary directory")
+
+// WithTempDir returns a copy of the context, ctx, that configures work
+// groups started with it to provide a temporary directory to the group,
+// see GroupTempDir, and a subdirectory of it to each worker, see TempDir.
+// The directory of a group is created on first use, with a name given by
+// pattern as for os.MkdirTemp, and it is removed with its contents when
+// the work group returns, even if it returns by panicking. The directory
+// of a nested work group is created in the directory of its parent worker.
+func WithTempDir(ctx context.Context, pattern string) context.Context {
+	return context.WithValue(ctx, tempKey{}, pattern)
+}
+
+// GroupTempDir returns the temporary directory of the
+// work group of the worker with the context, ctx.
+func GroupTempDir(ctx context.Context) (string, error) {
+	t, ok := ctx.Value(groupTempKey{}).(*tempDir)
+	if !ok {
+		return "", ErrNoTempDir
+	}
+	return t.path()
+}
+
+// TempDir returns the temporary directory of the worker with the
+// context, ctx, which is a subdirectory of the directory of its
+// work group named with the index of the worker.
+func TempDir(ctx context.Context) (string, error) {
+	w, ok := ctx.Value(workerTempKey{}).(*workerTemp)
+	if !ok {
+		return "", ErrNoTempDir
+	}
+	return w.path()
+}
+
+// tempDir is the temporary directory of a work group.
+type tempDir struct {
+	pattern string
+	parent  *workerTemp
+
+	mutex sync.Mutex
+	dir   string
+}
+
+func newTempDir(ctx context.Context) *tempDir {
+	pattern, ok := ctx.Value(tempKey{}).(string)
+	if !ok {
+		return nil
+	}
+	parent, _ := ctx.Value(workerTempKey{}).(*workerTemp)
+	return &tempDir{pattern: pattern, parent: parent}
+}
+
+// path returns the directory, which is created on first use.
+func (t *tempDir) path() (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.dir != "" {
+		return t.dir, nil
+	}
+
+	var base string
+	if t.parent != nil {
+		var err error
+		if base, err = t.parent.path(); err != nil {
+			return "", err
+		}
+	}
+	dir, err := os.MkdirTemp(base, t.pattern)
+	if err != nil {
+		return "", err
+	}
+	t.dir = dir
+	return dir, nil
+}
+
+// remove removes the directory and its contents, if it was created.
+func (t *tempDir) remove() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.dir != "" {
+		os.RemoveAll(t.dir)
+		t.dir = ""
+	}
+}
+
+// workerTemp is the temporary directory of a worker.
+type workerTemp struct {
+	dir   *tempDir
+	index int
+}
+
+func (w *workerTemp) path() (string, error) {
+	dir, err := w.dir.path()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, strconv.Itoa(w.index))
+	if err := os.Mkdir(path, 0o700); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	return path, nil
+}
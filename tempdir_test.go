@@ -0,0 +1,76 @@
+package workgroup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestTempDir(t *testing.T) {
+
+	var mutex sync.Mutex
+	var dirs []string
+
+	ctx := WithTempDir(context.Background(), "workgroup-test-*")
+	err := WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+		dir, err := TempDir(ctx)
+		if err != nil {
+			return err
+		}
+		if filepath.Base(dir) != strconv.Itoa(index) {
+			t.Errorf("Unexpected worker directory: %s", dir)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "scratch"), []byte("data"), 0o600); err != nil {
+			return err
+		}
+
+		gdir, err := GroupTempDir(ctx)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		dirs = append(dirs, gdir)
+		mutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(dirs) != 3 || dirs[0] != dirs[1] || dirs[1] != dirs[2] {
+		t.Fatalf("Expecting workers to share the group directory: %v", dirs)
+	}
+	if _, err := os.Stat(dirs[0]); !os.IsNotExist(err) {
+		t.Fatalf("Expecting group directory removed: %v", err)
+	}
+}
+
+func TestTempDirPanic(t *testing.T) {
+
+	var dir string
+	ctx := WithTempDir(context.Background(), "workgroup-test-*")
+
+	func() {
+		defer func() {
+			recover()
+		}()
+		Work(ctx, NewSerial(), Repanic(nil), func(ctx context.Context) error {
+			dir, _ = TempDir(ctx)
+			panic("worker panic")
+		})
+	}()
+
+	if dir == "" {
+		t.Fatalf("Expecting worker directory")
+	}
+	if _, err := os.Stat(filepath.Dir(dir)); !os.IsNotExist(err) {
+		t.Fatalf("Expecting group directory removed after panic: %v", err)
+	}
+
+	if _, err := TempDir(context.Background()); err != ErrNoTempDir {
+		t.Fatalf("Expecting ErrNoTempDir, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type timeoutKey struct{}
+
+// ErrWorkerTimeout is the error of a worker that did not complete
+// within the timeout configured by WithWorkerTimeout.
+var ErrWorkerTimeout = errors.New("workgroup: worker timeout")
+
+// WithWorkerTimeout returns a copy of the context, ctx, that configures
+// the work groups started with it, and all work groups nested within
+// them, to run each worker with a context that is cancelled when the
+// worker has run for the duration, d. If the worker then completes with
+// an error, the error is replaced by an error that matches both the error
+// and ErrWorkerTimeout, using errors.Is, so that the manager can tell a
+// slow worker from the cancellation of the group. The deadline is
+// measured by the clock of the context, see WithClock.
+func WithWorkerTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+func timeoutFrom(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(timeoutKey{}).(time.Duration)
+	return d
+}
+
+// timeoutError is the error of a worker that timed out.
+type timeoutError struct {
+	err error
+}
+
+func (e *timeoutError) Error() string {
+	return ErrWorkerTimeout.Error() + ": " + e.err.Error()
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrWorkerTimeout
+}
+
+func (e *timeoutError) Unwrap() error {
+	return e.err
+}
+
+// timeoutWorker returns a worker that calls the worker, w, with a context
+// that has the timeout, d, and converts the error of w if it overruns.
+func timeoutWorker(d time.Duration, w Worker) Worker {
+	return func(ctx context.Context) error {
+		tctx, cancel := withTimeout(ctx, ClockFrom(ctx), d)
+		defer cancel()
+
+		err := w(tctx)
+		if err != nil && ctx.Err() == nil && tctx.Err() == context.DeadlineExceeded {
+			err = &timeoutError{err: err}
+		}
+		return err
+	}
+}
@@ -0,0 +1,37 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkerTimeout(t *testing.T) {
+
+	ctx := WithWorkerTimeout(context.Background(), 10*time.Millisecond)
+
+	err := WorkFor(ctx, nil, CancelNeverFirstError(), 3, func(ctx context.Context, index int) error {
+		if index == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrWorkerTimeout) {
+		t.Fatalf("Expecting worker timeout error, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expecting error of worker to be wrapped, got %v", err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Work(cctx, nil, nil, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expecting cancellation of group not to be a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// ValueManager is a Manager for workers that return a value of type T,
+// which also selects the value of the work group from the values of the
+// workers, see WorkValue. When used as a Manager, without values, it
+// manages the workers like the manager it wraps and selects no value.
+type ValueManager[T any] interface {
+	Manager
+
+	// ManageValue is the same as Manage, and is called instead
+	// of Manage with the value, v, returned by the worker.
+	ManageValue(ctx context.Context, c Canceller, idx int, v T, err *error) int
+
+	// Value returns the selected value and the index of the worker that
+	// returned it, or false if no value has been selected.
+	Value() (v T, idx int, ok bool)
+}
+
+// SelectValue wraps a Manager, m, and selects the value of the first
+// worker to complete for which the function, accept, returns true,
+// given the index and the error of the worker. If manager, m, is not
+// provided then DefaultManager is called to obtain the default manager.
+func SelectValue[T any](m Manager, accept func(idx int, err error) bool) ValueManager[T] {
+	if m == nil {
+		m = DefaultManager()
+	}
+	return &valueManager[T]{m: m, accept: accept}
+}
+
+// FirstSuccessValue is the same as CancelOnFirstSuccess, and selects the
+// value of the first worker to complete without error, so that the winner
+// of a race can return its result without the use of shared variables.
+func FirstSuccessValue[T any](opts ...ManagerOption) ValueManager[T] {
+	return SelectValue[T](CancelOnFirstSuccess(opts...), func(idx int, err error) bool {
+		return err == nil
+	})
+}
+
+// FirstCompleteValue is the same as CancelOnFirstComplete, and selects
+// the value of the first worker to complete, whether or not with error.
+func FirstCompleteValue[T any](opts ...ManagerOption) ValueManager[T] {
+	return SelectValue[T](CancelOnFirstComplete(opts...), func(idx int, err error) bool {
+		return true
+	})
+}
+
+type valueManager[T any] struct {
+	m      Manager
+	accept func(idx int, err error) bool
+
+	mutex    sync.Mutex
+	selected bool
+	idx      int
+	v        T
+}
+
+func (m *valueManager[T]) Error() error {
+	return m.m.Error()
+}
+
+func (m *valueManager[T]) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	return m.m.Manage(ctx, c, idx, err)
+}
+
+func (m *valueManager[T]) ManageValue(ctx context.Context, c Canceller, idx int, v T, err *error) int {
+	m.mutex.Lock()
+	if !m.selected && m.accept(idx, *err) {
+		m.selected = true
+		m.idx = idx
+		m.v = v
+	}
+	m.mutex.Unlock()
+	return m.m.Manage(ctx, c, idx, err)
+}
+
+func (m *valueManager[T]) Value() (T, int, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.v, m.idx, m.selected
+}
+
+// WorkValue arranges for the workers to be executed, like Work, and
+// returns the value selected by the manager, m, such as the value of
+// the first worker to succeed for FirstSuccessValue. If the work group
+// has an error, then the error is returned with the zero value of T.
+// See documention for Work() for details.
+func WorkValue[T any](ctx context.Context, e Executer, m ValueManager[T], workers ...Task[T]) (T, error) {
+	values := make([]T, len(workers))
+	err := WorkFor(ctx, e, &valueAdapter[T]{m: m, values: values}, len(workers), func(ctx context.Context, index int) error {
+		v, err := workers[index](ctx)
+		values[index] = v
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v, _, _ := m.Value()
+	return v, nil
+}
+
+// valueAdapter is a Manager that passes the values of the
+// workers, stored by index before they complete, to the
+// ValueManager, m.
+type valueAdapter[T any] struct {
+	m      ValueManager[T]
+	values []T
+}
+
+func (a *valueAdapter[T]) Error() error {
+	return a.m.Error()
+}
+
+func (a *valueAdapter[T]) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	return a.m.ManageValue(ctx, c, idx, a.values[idx], err)
+}
+
+// WorkAny arranges for the tasks to race, like Work with the manager
+// provided by CancelOnFirstSuccess, and returns the value of the first
+// task to complete without error, cancelling the others. If all of the
+// tasks fail, then the errors of the tasks are returned as a GroupError.
+// See documention for Work() for details.
+func WorkAny[T any](ctx context.Context, e Executer, tasks ...Task[T]) (T, error) {
+	a := Accumulate(CancelOnFirstSuccess())
+	v, err := WorkValue(ctx, e, SelectValue[T](a, func(idx int, err error) bool {
+		return err == nil
+	}), tasks...)
+	if err != nil {
+		if gerr := a.GroupError(); gerr != nil {
+			return v, gerr
+		}
+	}
+	return v, err
+}
@@ -0,0 +1,101 @@
+package workgroup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWorkValue(t *testing.T) {
+
+	failed := errors.New("failed")
+	m := FirstSuccessValue[string]()
+	v, err := WorkValue(context.Background(), NewSerial(), m,
+		func(ctx context.Context) (string, error) {
+			return "", failed
+		},
+		func(ctx context.Context) (string, error) {
+			return "winner", nil
+		},
+		func(ctx context.Context) (string, error) {
+			return "late", ctx.Err()
+		},
+	)
+	if err != nil || v != "winner" {
+		t.Fatalf("Expecting winner, got %q, %v", v, err)
+	}
+	if _, idx, ok := m.Value(); !ok || idx != 1 {
+		t.Fatalf("Expecting value of worker 1, got %d, %v", idx, ok)
+	}
+
+	v, err = WorkValue(context.Background(), NewSerial(), FirstSuccessValue[string](),
+		func(ctx context.Context) (string, error) {
+			return "ignored", failed
+		},
+	)
+	if err != failed || v != "" {
+		t.Fatalf("Expecting error %v, got %q, %v", failed, v, err)
+	}
+
+	n, err := WorkValue(context.Background(), NewSerial(), FirstCompleteValue[int](),
+		func(ctx context.Context) (int, error) {
+			return 1, nil
+		},
+		func(ctx context.Context) (int, error) {
+			return 2, ctx.Err()
+		},
+	)
+	if err != nil || n != 1 {
+		t.Fatalf("Expecting 1, got %d, %v", n, err)
+	}
+
+	// the default manager is used if no manager is provided
+	sm := SelectValue[int](nil, func(idx int, err error) bool {
+		return idx == 1
+	})
+	n, err = WorkValue(context.Background(), NewSerial(), sm,
+		func(ctx context.Context) (int, error) {
+			return 1, nil
+		},
+		func(ctx context.Context) (int, error) {
+			return 2, nil
+		},
+	)
+	if err != nil || n != 2 {
+		t.Fatalf("Expecting 2, got %d, %v", n, err)
+	}
+}
+
+func TestWorkAny(t *testing.T) {
+
+	v, err := WorkAny(context.Background(), NewUnlimited(),
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		func(ctx context.Context) (string, error) {
+			return "replica", nil
+		},
+	)
+	if err != nil || v != "replica" {
+		t.Fatalf("Expecting replica, got %q, %v", v, err)
+	}
+
+	errA := errors.New("a")
+	errB := errors.New("b")
+	_, err = WorkAny(context.Background(), NewUnlimited(),
+		func(ctx context.Context) (string, error) {
+			return "", errA
+		},
+		func(ctx context.Context) (string, error) {
+			return "", errB
+		},
+	)
+	var gerr *GroupError
+	if !errors.As(err, &gerr) || gerr.Len() != 2 {
+		t.Fatalf("Expecting GroupError of both tasks, got %v", err)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Expecting both errors, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"time"
+)
+
+type watchdogKey struct{}
+
+// WorkerInfo describes a running worker, see WithWatchdog.
+type WorkerInfo struct {
+	// Index is the index of the worker.
+	Index int
+
+	// Name is the name of the worker, see Named.
+	Name string
+
+	// Group is the name of the work group of the worker, see WithGroupName.
+	Group string
+
+	// Started is the time the worker started.
+	Started time.Time
+
+	// Elapsed is the time the worker has been running, see Scope.Dump,
+	// or the duration of the worker, see InfoManager.
+	Elapsed time.Duration
+
+	// Attempt is the number of times the worker was called, which
+	// is more than one if it was retried, see InfoManager and Retry.
+	Attempt int
+
+	// Stack is the stack of the goroutine of the worker,
+	// if configured by the WatchdogStack option.
+	Stack []byte
+}
+
+// WatchdogOption configures optional behavior of the watchdog.
+type WatchdogOption func(*watchdog)
+
+// WatchdogStack configures the watchdog to include the stack of
+// the goroutine of the stalled worker in the WorkerInfo, which
+// requires the stacks of all goroutines to be collected.
+func WatchdogStack() WatchdogOption {
+	return func(w *watchdog) {
+		w.stack = true
+	}
+}
+
+type watchdog struct {
+	d       time.Duration
+	onStall func(WorkerInfo)
+	stack   bool
+}
+
+// WithWatchdog returns a copy of the context, ctx, that configures the
+// work groups started with it, and all work groups nested within them,
+// to call the function, onStall, when a worker runs for longer than the
+// duration, d, without completing, so that a hang in one of thousands
+// of workers is not silent. The function is called on a new goroutine,
+// at most once for each worker. The time is measured by the clock of
+// the context, see WithClock.
+func WithWatchdog(ctx context.Context, d time.Duration, onStall func(WorkerInfo), opts ...WatchdogOption) context.Context {
+	w := &watchdog{d: d, onStall: onStall}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return context.WithValue(ctx, watchdogKey{}, w)
+}
+
+func watchdogFrom(ctx context.Context) *watchdog {
+	w, _ := ctx.Value(watchdogKey{}).(*watchdog)
+	return w
+}
+
+// watch watches the worker with the given index, that is started on the
+// calling goroutine, and returns a function to call when it completes.
+func (w *watchdog) watch(ctx context.Context, index int) (done func()) {
+	clock := ClockFrom(ctx)
+	info := WorkerInfo{Index: index, Started: clock.Now()}
+
+	var id []byte
+	if w.stack {
+		id = goroutineID()
+	}
+
+	stop := make(chan struct{})
+	timer := clock.NewTimer(w.d)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-timer.C():
+			if id != nil {
+				info.Stack = goroutineStack(id)
+			}
+			describe(ctx, &info)
+			w.onStall(info)
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+	}
+}
+
+// goroutineID returns the header, "goroutine N ",
+// of the stack of the calling goroutine.
+func goroutineID() []byte {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	if i := bytes.IndexByte(buf, '['); i > 0 {
+		return buf[:i]
+	}
+	return nil
+}
+
+// goroutineStack returns the stack of the goroutine with the header, id.
+func goroutineStack(id []byte) []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, id) {
+			return stack
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package workgroup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+
+	stalled := make(chan WorkerInfo, 1)
+	ctx := WithWatchdog(context.Background(), time.Millisecond, func(info WorkerInfo) {
+		stalled <- info
+	}, WatchdogStack())
+
+	// The worker does not complete until the watchdog has fired,
+	// so that the test does not depend on the time it takes.
+	var info WorkerInfo
+	WorkFor(ctx, nil, nil, 1, func(ctx context.Context, index int) error {
+		info = <-stalled
+		return nil
+	})
+
+	if info.Index != 0 {
+		t.Fatalf("Expecting worker 0 stalled, got %d", info.Index)
+	}
+	if !bytes.Contains(info.Stack, []byte("TestWatchdog")) {
+		t.Fatalf("Expecting stack of stalled worker:\n%s", info.Stack)
+	}
+}
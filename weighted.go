@@ -0,0 +1,119 @@
+package workgroup
+
+import (
+	"context"
+	"runtime"
+)
+
+type weightKey struct{}
+
+// weight records the units of a weighted executer held by a worker.
+type weight struct {
+	e *WeightedExecuter
+	n int
+}
+
+// WeightedExecuter is an executer that executes each function
+// with a cost and admits functions while the total cost of
+// the functions executing stays within a limit.
+type WeightedExecuter struct {
+	limit int
+	sem   *semaphore
+}
+
+// NewWeighted returns an executer that will execute functions while
+// the total cost of the functions executing is at most limit. If
+// limit <= 0 then the value provided by DefaultLimit will be used.
+// The cost of the workers of a work group can be declared with the
+// Weighted worker wrapper, otherwise each worker has a cost of one.
+func NewWeighted(limit int) *WeightedExecuter {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit <= 0 {
+		limit = runtime.NumCPU()
+	}
+	return &WeightedExecuter{
+		limit: limit,
+		sem:   newSemaphore(limit),
+	}
+}
+
+// Execute executes the function, f, with a cost of one.
+func (w *WeightedExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	w.ExecuteWeighted(ctx, 1, f)
+}
+
+// ExecuteWeighted waits until the cost can be admitted and then
+// executes the function, f, on a new goroutine. The cost is clamped
+// to the limit of the executer so that the function can be admitted.
+func (w *WeightedExecuter) ExecuteWeighted(ctx context.Context, cost int, f func(context.Context)) {
+	cost = w.clamp(cost)
+	w.sem.acquire(cost)
+
+	h := &weight{e: w, n: cost}
+	go func() {
+		defer func() {
+			w.sem.release(h.n)
+		}()
+		f(context.WithValue(ctx, weightKey{}, h))
+	}()
+}
+
+func (w *WeightedExecuter) clamp(cost int) int {
+	if cost < 0 {
+		return 0
+	}
+	if cost > w.limit {
+		return w.limit
+	}
+	return cost
+}
+
+// lend releases a unit held by the worker that is starting
+// a nested group with this executer, see limited.lend().
+func (w *WeightedExecuter) lend() func() {
+	w.sem.release(1)
+	return func() {
+		w.sem.acquire(1)
+	}
+}
+
+// Weighted returns a worker that declares the cost of the worker, w.
+// When executed by a WeightedExecuter, the worker waits until its cost
+// is admitted by the executer before calling w. When executed by the
+// executer of NewLimited, the worker waits until it can occupy cost
+// goroutines of the limit, so that heavy workers reduce the effective
+// parallelism while light workers run at full concurrency. When executed
+// by the executer of NewSemaphoreExecuter, the worker waits until its cost
+// is acquired from the semaphore. In either case, the worker returns the
+// error of its context if it is done first. With the executers of
+// NewLimited and NewSemaphoreExecuter, the cost is only declared by a
+// worker that holds a slot of the executer, it is ignored for the workers
+// of nested groups with other executers, such as those of Hedge, and for
+// a worker whose slot is lent to a nested group or already weighed. With
+// other executers the cost is ignored.
+func Weighted(cost int, w Worker) Worker {
+	return func(ctx context.Context) error {
+		if h, ok := ctx.Value(weightKey{}).(*weight); ok {
+			cost := h.e.clamp(cost)
+			if cost != h.n {
+				h.e.sem.release(h.n)
+				if err := h.e.sem.acquireContext(ctx, cost); err != nil {
+					h.e.sem.acquire(h.n)
+					return err
+				}
+				h.n = cost
+			}
+		} else {
+			restore, err := weighSlot(ctx, cost)
+			if err != nil {
+				return err
+			}
+			if restore != nil {
+				defer restore()
+			}
+		}
+		return w(ctx)
+	}
+}
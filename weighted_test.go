@@ -0,0 +1,157 @@
+package workgroup
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWeightedWork(t *testing.T) {
+
+	var mutex sync.Mutex
+	var cost, peak int
+
+	worker := func(c int) Worker {
+		return Weighted(c, func(ctx context.Context) error {
+			mutex.Lock()
+			cost += c
+			if cost > peak {
+				peak = cost
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mutex.Lock()
+			cost -= c
+			mutex.Unlock()
+			return nil
+		})
+	}
+
+	workers := make([]Worker, 1000)
+	for i := range workers {
+		if i%10 == 0 {
+			workers[i] = worker(5)
+		} else {
+			workers[i] = worker(1)
+		}
+	}
+
+	err := Work(context.Background(), NewWeighted(8), nil, workers...)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+	if peak > 8 {
+		t.Fatalf("Total cost of workers exceeded limit: %d", peak)
+	}
+}
+
+func TestWeightedLimited(t *testing.T) {
+
+	var mutex sync.Mutex
+	var cost, peak int
+
+	worker := func(c int) Worker {
+		return Weighted(c, func(ctx context.Context) error {
+			mutex.Lock()
+			cost += c
+			if cost > peak {
+				peak = cost
+			}
+			mutex.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mutex.Lock()
+			cost -= c
+			mutex.Unlock()
+			return nil
+		})
+	}
+
+	workers := make([]Worker, 1000)
+	for i := range workers {
+		switch {
+		case i%100 == 0:
+			workers[i] = worker(8)
+		case i%10 == 0:
+			workers[i] = worker(3)
+		default:
+			workers[i] = worker(1)
+		}
+	}
+
+	err := Work(context.Background(), NewLimited(8), nil, workers...)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+	if peak > 8 {
+		t.Fatalf("Total cost of workers exceeded limit: %d", peak)
+	}
+}
+
+func TestWeightedNested(t *testing.T) {
+
+	e := NewWeighted(4)
+	err := WorkFor(context.Background(), e, nil, 2, func(ctx context.Context, index int) error {
+		// the weight of the worker is not shared with the nested workers
+		return WorkFor(ctx, NewUnlimited(), nil, 4, func(ctx context.Context, index int) error {
+			return Weighted(3, func(ctx context.Context) error {
+				time.Sleep(time.Millisecond)
+				return nil
+			})(ctx)
+		})
+	})
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	// the units of the executer have all been released
+	err = Work(context.Background(), e, nil, Weighted(4, func(ctx context.Context) error { return nil }))
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+}
+
+func TestWeightedLimitedSlot(t *testing.T) {
+
+	e := NewLimited(2).(*limited)
+
+	// the cost of a worker is declared once
+	var held int
+	err := WorkFor(context.Background(), e, nil, 1, func(ctx context.Context, index int) error {
+		return Weighted(2, func(ctx context.Context) error {
+			return Weighted(2, func(ctx context.Context) error {
+				held = len(e.ch)
+				return nil
+			})(ctx)
+		})(ctx)
+	})
+	if err != nil || held != 2 {
+		t.Fatalf("Expecting 2 slots held, got %d: %v", held, err)
+	}
+
+	// a worker stops waiting for slots once its context is done,
+	// the slot of the other worker is held until then
+	weighed := make(chan struct{})
+	err = WorkFor(context.Background(), e, nil, 2, func(ctx context.Context, index int) error {
+		if index == 0 {
+			defer close(weighed)
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+			return Weighted(2, func(ctx context.Context) error {
+				return nil
+			})(ctx)
+		}
+		<-weighed
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expecting error %v, got %v", context.Canceled, err)
+	}
+	if n := len(e.ch); n != 0 {
+		t.Fatalf("Expecting all slots released, got %d held", n)
+	}
+}
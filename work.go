@@ -2,7 +2,12 @@ package workgroup
 
 import (
 	"context"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Worker is a function that performs work
@@ -11,6 +16,40 @@ type Worker func(context.Context) error
 // IdxWorker is a function that performs work with for a given index
 type IdxWorker func(context.Context, int) error
 
+type indexKey struct{}
+
+type decoratorKey struct{}
+
+// WithContextDecorator returns a copy of the context, ctx, that configures
+// the work group started with it to call the function, fn, with the context
+// and index of each worker, before the worker is submitted to the executer,
+// and to execute the worker with the context returned by fn. The context of
+// a worker can be enriched, for example, with a request ID or logger, or
+// with a priority or key for the executer, see WithPriority and WithKey.
+// The returned context must be derived from the context of the worker.
+// Work groups nested in the group are not decorated, but their workers
+// inherit the context of the parent worker.
+func WithContextDecorator(ctx context.Context, fn func(context.Context, int) context.Context) context.Context {
+	return context.WithValue(ctx, decoratorKey{}, fn)
+}
+
+func decoratorFrom(ctx context.Context) func(context.Context, int) context.Context {
+	fn, _ := ctx.Value(decoratorKey{}).(func(context.Context, int) context.Context)
+	return fn
+}
+
+// IndexFromContext returns the index of the worker with the context, ctx,
+// within its work group, so that code called by the worker can identify
+// the worker, for example when logging, without the index being passed
+// through every call. The result is false if ctx is not of a worker.
+// The workers of Work and WorkFor are indexed from zero, the workers
+// received from a channel, by WorkChan, WorkChanFor and WorkChanCollect,
+// are indexed from one.
+func IndexFromContext(ctx context.Context) (int, bool) {
+	index, ok := ctx.Value(indexKey{}).(int)
+	return index, ok
+}
+
 // Work arranges for a group of workers to be executed
 // and then waits for these workers to complete.
 // The executer, e, is responsible for executing these workers
@@ -19,142 +58,769 @@ type IdxWorker func(context.Context, int) error
 // If executer, e, is not provided then DefaultExecuter
 // is called to obtain the default. If manager, m, is not provied
 // then DefaultManager is called be obtain the default manager.
+// The defaults can be overridden by the context, see NewContext.
+//
+// A work group started by a worker is nested in the work group of
+// that worker. A nested work group does not inherit the executer of
+// the enclosing group unless the executer provided by Inherit() is
+// used, in which case the executer of the enclosing work group is
+// used or DefaultExecuter if the group is not nested. If the context
+// is configured by InheritExecuter, then a nil executer is treated
+// the same as the executer provided by Inherit(). When a nested
+// group uses the same limited executer as the enclosing group, the
+// slot held by the parent worker is lent to the nested group while
+// it runs, so that nesting does not deadlock the executer.
+//
+// Every worker is invoked, even if the context of the group has been
+// cancelled by the time the executer starts the worker, regardless of
+// the executer. Workers are expected to check their context. If the
+// context is configured by SkipIfCancelled, then a worker whose
+// context is cancelled when it is submitted is not passed to the
+// executer, and one whose context is cancelled by the time it is
+// started is not invoked.
 func Work(ctx context.Context, e Executer, m Manager, g ...Worker) error {
-	if ctx == nil {
-		ctx = context.TODO()
+	grp := newGroup(ctx, e, m)
+	defer grp.close()
+	return grp.work(g)
+}
+
+// Group returns a worker that immediately calls the
+// Work() function to execute the given group of workers.
+// To share the concurrency limit of the enclosing work
+// group, rather than multiply it, provide the executer
+// of the enclosing group or the executer, Inherit().
+func Group(e Executer, m Manager, g ...Worker) Worker {
+	return func(ctx context.Context) error {
+		return Work(ctx, e, m, g...)
 	}
+}
+
+// WorkFor arranges for the worker, w, to be executed n times
+// and waits for these workers to complete before returning.
+// See documention for Work() for details.
+//
+// The workers are generated as they are submitted, so that with a
+// limited executer, the memory of the group is bounded by the limit,
+// rather than by n, since Execute blocks until a worker completes. If
+// the context is configured by StopIfCancelled, then no further workers
+// are generated once the group is cancelled.
+func WorkFor(ctx context.Context, e Executer, m Manager, n int, w IdxWorker) error {
+	grp := newGroup(ctx, e, m)
+	defer grp.close()
+	return grp.workFor(n, w)
+}
 
-	if e == nil {
-		e = DefaultExecuter()
+// GroupFor returns a worker that immediately calls the
+// WorkFor() function to execute the worker n times.
+// See documention for Group() for details.
+func GroupFor(e Executer, m Manager, n int, w IdxWorker) Worker {
+	return func(ctx context.Context) error {
+		return WorkFor(ctx, e, m, n, w)
 	}
+}
 
-	if m == nil {
-		m = DefaultManager()
+// WorkForChunked arranges for the range of indexes from 0 to n to be
+// split into chunks of at most chunkSize indexes, and for the worker, w,
+// to be executed once for each chunk with the start and end, exclusive,
+// of the chunk, and waits for these workers to complete before returning.
+// Processing a large number of items in ranges avoids the allocation and
+// scheduling of a worker for each item. The index of a worker is the index
+// of its chunk. If chunkSize <= 0 then the range is split into four chunks
+// for each of the goroutines provided by DefaultLimit, unless the context
+// is configured by WithCheckpoint, in which case no worker is executed and
+// the error is ErrInvalidChunkSize. See documention for Work() for details.
+func WorkForChunked(ctx context.Context, e Executer, m Manager, n, chunkSize int, w func(ctx context.Context, start, end int) error) error {
+	if s, _ := ctx.Value(checkpointKey{}).(CheckpointStore); s != nil && chunkSize <= 0 {
+		return ErrInvalidChunkSize
+	}
+	if chunkSize <= 0 {
+		limit := DefaultLimit
+		if limit <= 0 {
+			limit = runtime.NumCPU()
+		}
+		chunkSize = (n + 4*limit - 1) / (4 * limit)
+	}
+	if chunkSize <= 0 {
+		chunkSize = 1
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	chunks := (n + chunkSize - 1) / chunkSize
+	return WorkFor(ctx, e, m, chunks, func(ctx context.Context, index int) error {
+		start := index * chunkSize
+		end := start + chunkSize
+		if end > n {
+			end = n
+		}
+		return w(ctx, start, end)
+	})
+}
 
-	wg := &sync.WaitGroup{}
+// WorkChan arranges for the group of workers provided by channel, g,
+// to be executed and waits for the channel to be closed and all
+// workers to complete. See documention for Work() for details.
+// Unlike Work, the workers are indexed from one, in the order that
+// they are received from the channel, which the managers observe.
+// A producer that can not block on sending to the channel may
+// observe the backlog of the group, see WithBacklog.
+//
+// If the context of the group is cancelled, then WorkChan stops
+// receiving from the channel and waits for the submitted workers to
+// complete, so that it returns promptly even if the producer stalls.
+// If the context is configured by DrainOnCancel, then WorkChan
+// continues to receive workers until the channel is closed.
+func WorkChan(ctx context.Context, e Executer, m Manager, g <-chan Worker) error {
+	grp := newGroup(ctx, e, m)
+	defer grp.close()
+
+	grp.first = 1
+	for i := grp.first; ; i++ {
+		w, ok := receive(grp, g)
+		if !ok {
+			break
+		}
+		grp.execute(i, w)
+	}
 
-	wg.Add(len(g))
-	for i, w := range g {
-		index := i
-		worker := w
-		e.Execute(ctx, func(ctx context.Context) {
-			defer wg.Done()
+	return grp.wait()
+}
 
-			var err error
-			defer m.Manage(ctx, CancellerFunc(cancel), index, &err)
-			err = worker(ctx)
+// GroupChan returns a worker that immediately calls
+// WorkChan to execute the group of workers provided
+// by the channel.
+func GroupChan(e Executer, m Manager, g <-chan Worker) Worker {
+	return func(ctx context.Context) error {
+		return WorkChan(ctx, e, m, g)
+	}
+}
+
+// WorkChanFor arranges for the function, fn, to be executed for each
+// item provided by the channel, items, and waits for the channel to be
+// closed and all workers to complete. The index of a worker is the
+// position of its item in the channel, counted from one as by WorkChan.
+// See documention for WorkChan() for details.
+func WorkChanFor[T any](ctx context.Context, e Executer, m Manager, items <-chan T, fn func(context.Context, T) error) error {
+	grp := newGroup(ctx, e, m)
+	defer grp.close()
+
+	grp.first = 1
+	for i := grp.first; ; i++ {
+		item, ok := receive(grp, items)
+		if !ok {
+			break
+		}
+		grp.execute(i, func(ctx context.Context) error {
+			return fn(ctx, item)
 		})
 	}
 
-	wg.Wait()
+	return grp.wait()
+}
 
-	return m.Error()
+type drainKey struct{}
+
+// DrainOnCancel returns a copy of the context, ctx, that configures work
+// groups started with it, and all work groups nested within them, to
+// continue to receive workers from the channel of WorkChan, and of
+// WorkChanCollect, until it is closed, after the group is cancelled, so
+// that the producer does not block. The received workers are handled as
+// described by Work. By default the group stops receiving when it is
+// cancelled.
+func DrainOnCancel(ctx context.Context) context.Context {
+	return context.WithValue(ctx, drainKey{}, true)
 }
 
-// Group returns a worker that immediately calls the
-// Work() function to execute the given group of workers.
-func Group(e Executer, m Manager, g ...Worker) Worker {
-	return func(ctx context.Context) error {
-		return Work(ctx, e, m, g...)
+// receive receives the next value from the channel, c, for the group, g.
+// The result is false if the channel is closed, or if the group is
+// cancelled and does not drain the channel.
+func receive[T any](g *group, c <-chan T) (v T, ok bool) {
+	select {
+	case v, ok = <-c:
+		return v, ok
+	case <-g.stop:
+		return v, false
+	case <-g.ctx.Done():
+	}
+	if !g.drain {
+		return v, false
 	}
+	v, ok = <-c
+	return v, ok
 }
 
-// WorkFor arranges for the worker, w, to be executed n times
-// and waits for these workers to complete before returning.
-// See documention for Work() for details.
-func WorkFor(ctx context.Context, e Executer, m Manager, n int, w IdxWorker) error {
+// group contains the state of a single execution of a work group.
+type group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	e      Executer
+	m      Manager
+	wg     sync.WaitGroup
+	out    *output
+
+	reclaim func()
+
+	parent    *group
+	first     int
+	running   int64
+	expected  int64
+	submitted int64
+
+	mutex   sync.Mutex
+	pending int
+	backlog func(int)
+
+	reporter *reporter
+	hook     *firstErrorHook
+	tmp      *tempDir
+	skip     bool
+	drain    bool
+	halt     bool
+	label    string
+	watchdog *watchdog
+	inflight *inflight
+	timeout  time.Duration
+	admit    *admission
+	keys     func(int) string
+	decorate func(context.Context, int) context.Context
+	name     string
+	names    map[int]string
+	named    int32
+	logger   groupLogger
+	start    time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	handle   *Handle
+	paused   int32
+	resume   chan struct{}
+	admitter admitter
+	info     InfoManager
+
+	canceller Canceller
+	jobs      *sync.Pool
+
+	checkpoint CheckpointStore
+	base       context.Context
+
+	stall     *stallMonitor
+	listeners int32
+	progress  int64
+	failed    int64
+}
+
+func newGroup(ctx context.Context, e Executer, m Manager) *group {
+	return new(group).init(ctx, e, m)
+}
+
+// init initializes the group, g, which is either new or reused, see Runner.
+func (g *group) init(ctx context.Context, e Executer, m Manager) *group {
 	if ctx == nil {
 		ctx = context.TODO()
 	}
 
-	if e == nil {
-		e = DefaultExecuter()
+	de, dm := defaultsFrom(ctx)
+	if e == nil && de != nil {
+		e = de
+	} else if e == nil {
+		if ctx.Value(inheritKey{}) != nil {
+			e = inherited(ctx)
+		} else {
+			e = DefaultExecuter()
+		}
+	} else if _, ok := e.(inherit); ok {
+		e = inherited(ctx)
 	}
 
+	if m == nil && dm != nil {
+		m = dm()
+	}
 	if m == nil {
 		m = DefaultManager()
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	*g = group{e: e, m: m, stop: make(chan struct{}), done: make(chan struct{})}
+	g.base = ctx
+	g.admitter, _ = m.(admitter)
+	g.info = infoManager(m)
+	g.parent, _ = ctx.Value(groupKey{}).(*group)
+	g.reclaim = lendSlot(ctx, e)
+
+	if neverCancels(m) && ctx.Value(sharedContextKey{}) != nil {
+		// the context is not cancelled by the manager, see WithSharedContext
+		g.ctx, g.cancel = ctx, func() {}
+	} else {
+		g.ctx, g.cancel = context.WithCancel(ctx)
+	}
+	if ctx.Value(handleKey{}) != nil {
+		// the running workers of a handle are tracked, see Scope.Dump
+		g.inflight = newInflight(ClockFrom(ctx))
+		g.ctx = context.WithValue(g.ctx, handleKey{}, nil)
+	}
+	g.canceller = CancellerFunc(g.cancel)
+	g.ctx = context.WithValue(g.ctx, executerKey{}, e)
+	g.ctx = context.WithValue(g.ctx, groupKey{}, g)
+	if ctx.Value(jobKey{}) != nil {
+		// goroutines are registered with the job of a worker, see Go
+		g.ctx = context.WithValue(g.ctx, jobKey{}, nil)
+	}
+	g.out = newOutput(ctx)
+	if ctx.Value(attemptKey{}) != nil {
+		g.ctx = context.WithValue(g.ctx, attemptKey{}, nil)
+	}
+	if g.name, _ = ctx.Value(groupNameKey{}).(string); g.name != "" {
+		g.ctx = context.WithValue(g.ctx, groupNameKey{}, nil)
+	}
+	g.skip = ctx.Value(skipKey{}) != nil
+	g.drain = ctx.Value(drainKey{}) != nil
+	g.halt = ctx.Value(stopKey{}) != nil
+	g.label, _ = ctx.Value(labelKey{}).(string)
+	g.watchdog = watchdogFrom(ctx)
+	if g.logger = loggerFrom(ctx); g.logger != nil {
+		g.start = ClockFrom(ctx).Now()
+	}
+	g.timeout = timeoutFrom(ctx)
+	g.admit = admissionFrom(ctx)
+	if g.checkpoint, _ = ctx.Value(checkpointKey{}).(CheckpointStore); g.checkpoint != nil {
+		g.ctx = context.WithValue(g.ctx, checkpointKey{}, nil)
+	}
+	if ctx.Value(weightKey{}) != nil {
+		// the weight is held by the worker that starts the group
+		g.ctx = context.WithValue(g.ctx, weightKey{}, nil)
+	}
+	if ctx.Value(adaptiveKey{}) != nil {
+		// the outcome is recorded for the worker that starts the group
+		g.ctx = context.WithValue(g.ctx, adaptiveKey{}, nil)
+	}
+	if g.keys, _ = ctx.Value(keyFuncKey{}).(func(int) string); g.keys != nil {
+		g.ctx = context.WithValue(g.ctx, keyFuncKey{}, nil)
+	}
+	if g.decorate = decoratorFrom(ctx); g.decorate != nil {
+		g.ctx = context.WithValue(g.ctx, decoratorKey{}, nil)
+	}
+	if g.backlog = backlogFrom(ctx); g.backlog != nil {
+		g.ctx = context.WithValue(g.ctx, backlogKey{}, nil)
+	}
+	if g.tmp = newTempDir(ctx); g.tmp != nil {
+		g.ctx = context.WithValue(g.ctx, groupTempKey{}, g.tmp)
+	}
+	if fn := reportFrom(ctx); fn != nil {
+		g.reporter = newReporter(fn, ClockFrom(ctx))
+		g.ctx = context.WithValue(g.ctx, reportKey{}, nil)
+		g.ctx = context.WithValue(g.ctx, finishKey{}, nil)
+	}
+	if g.hook = newFirstErrorHook(ctx); g.hook != nil {
+		g.ctx = context.WithValue(g.ctx, firstErrorKey{}, nil)
+	}
+	if g.stall = stallMonitorFrom(ctx); g.stall != nil {
+		g.inflight = newInflight(ClockFrom(ctx))
+		go g.stall.monitor(g)
+	}
+	if fn, ok := ctx.Value(scopeKey{}).(func(*Scope)); ok {
+		if g.inflight == nil {
+			g.inflight = newInflight(ClockFrom(ctx))
+		}
+		g.ctx = context.WithValue(g.ctx, scopeKey{}, nil)
+		fn(&Scope{g: g})
+	}
+	return g
+}
 
-	wg := &sync.WaitGroup{}
+// execute arranges for the worker, w, with the given index to be
+// executed by the executer and managed by the manager of the group.
+func (g *group) execute(index int, w Worker) {
+	j := g.job(index)
+	j.w = w
+	g.submit(j)
+}
 
-	wg.Add(n)
-	for i := 0; i < n; i++ {
-		index := i
-		e.Execute(ctx, func(ctx context.Context) {
-			defer wg.Done()
+// executeFor is the same as execute for the worker, w, with an index,
+// which avoids the allocation of a Worker that binds the index.
+func (g *group) executeFor(index int, w IdxWorker) {
+	j := g.job(index)
+	j.iw = w
+	g.submit(j)
+}
 
-			var err error
-			defer m.Manage(ctx, CancellerFunc(cancel), index, &err)
-			err = w(ctx, index)
-		})
+// job returns a new or reused job for the worker with the given index.
+func (g *group) job(index int) *job {
+	var j *job
+	if g.jobs != nil {
+		j, _ = g.jobs.Get().(*job)
+	}
+	if j == nil {
+		j = &job{}
+		j.fn = j.run
 	}
+	j.g, j.index = g, index
+	return j
+}
 
-	wg.Wait()
+// job is a worker of a group, it is also the context of the worker,
+// so that the index of the worker is provided without an allocation,
+// see IndexFromContext. The state of the worker is kept in the job,
+// so that it is allocated once for each worker.
+type job struct {
+	context.Context
+	g         *group
+	index     int
+	w         Worker
+	iw        IdxWorker
+	out       *workerWriter
+	submitted time.Time
+	skipped   error
+	err       error
+	ran       bool
+	started   time.Time
+	fn        func(context.Context)
+
+	// live counts the job and the goroutines started by Go
+	// with its context, which are running, see register.
+	// slot is whether the worker holds a slot of the executer
+	// of the group, which is borrowed until lent is closed.
+	mutex sync.Mutex
+	live  int
+	slot  bool
+	lent  chan struct{}
+}
 
-	return m.Error()
+func (j *job) Value(key interface{}) interface{} {
+	switch key.(type) {
+	case indexKey:
+		return j.index
+	case jobKey:
+		return j
+	}
+	return j.Context.Value(key)
 }
 
-// GroupFor returns a worker that immediately calls the
-// WorkFor() function to execute the worker n times.
-func GroupFor(e Executer, m Manager, n int, w IdxWorker) Worker {
-	return func(ctx context.Context) error {
-		return WorkFor(ctx, e, m, n, w)
+// release releases the job to the pool, p, to be reused, see Runner.
+func (j *job) release(p *sync.Pool) {
+	*j = job{fn: j.fn}
+	p.Put(j)
+}
+
+// worker returns the worker of the job.
+func (j *job) worker() Worker {
+	if j.w == nil {
+		iw, index := j.iw, j.index
+		j.w = func(ctx context.Context) error {
+			return iw(ctx, index)
+		}
 	}
+	return j.w
 }
 
-// WorkChan arranges for the group of workers provided by channel, g,
-// to be executed and waits for the channel to be closed and all
-// workers to complete. See documention for Work() for details.
-func WorkChan(ctx context.Context, e Executer, m Manager, g <-chan Worker) error {
-	if ctx == nil {
-		ctx = context.TODO()
+// call calls the worker of the job with the context, ctx.
+func (j *job) call(ctx context.Context) error {
+	if j.iw != nil && j.w == nil {
+		return j.iw(ctx, j.index)
 	}
+	return j.w(ctx)
+}
 
-	if e == nil {
-		e = DefaultExecuter()
+// submit submits the job to the executer of the group.
+func (g *group) submit(j *job) {
+	g.wg.Add(1)
+	atomic.AddInt64(&g.running, 1)
+	atomic.AddInt64(&g.submitted, 1)
+	g.report(1)
+
+	index := j.index
+	j.Context = g.ctx
+	j.live = 1
+	var ctx context.Context = j
+	if g.out != nil {
+		j.out = g.out.writer(index)
+		ctx = context.WithValue(ctx, writerKey{}, j.out)
+	}
+	if g.keys != nil {
+		ctx = context.WithValue(ctx, keyKey{}, g.keys(index))
+	}
+	if g.tmp != nil {
+		ctx = context.WithValue(ctx, workerTempKey{}, &workerTemp{dir: g.tmp, index: index})
+	}
+	if g.decorate != nil {
+		ctx = g.decorate(ctx, index)
 	}
 
-	if m == nil {
-		m = DefaultManager()
+	if g.reporter != nil {
+		j.submitted = g.reporter.clock.Now()
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	// A worker that is skipped when it is submitted
+	// is managed on the calling goroutine.
+	g.gate(ctx)
+	if g.stopped() {
+		j.skipped = context.Canceled
+	} else if g.skip && ctx.Err() != nil {
+		j.skipped = ctx.Err()
+	} else if g.admit != nil && !g.admit.admit(ctx, index) {
+		j.skipped = context.DeadlineExceeded
+	}
 
-	wg := &sync.WaitGroup{}
+	if j.skipped != nil {
+		j.run(ctx)
+		return
+	}
+	g.e.Execute(ctx, j.fn)
+}
 
-	i := 0
-	for w := range g {
-		wg.Add(1)
-		i++
-		index := i
-		worker := w
-		e.Execute(ctx, func(ctx context.Context) {
-			defer wg.Done()
+// run runs the job with the context, ctx, provided by the executer.
+func (j *job) run(ctx context.Context) {
+	g, index := j.g, j.index
+	if g.reporter != nil {
+		g.reporter.latency.record(g.reporter.clock.Now().Sub(j.submitted))
+	}
+	defer g.wg.Done()
+	defer j.leave()
+	defer atomic.AddInt64(&g.running, -1)
+	if g.stall != nil {
+		atomic.AddInt64(&g.progress, 1)
+		defer atomic.AddInt64(&g.progress, 1)
+	}
+	defer g.report(-1)
+	defer g.forget(index)
+	if g.name != "" {
+		// count after the manager, which may recover a panic
+		defer func() {
+			if j.err != nil {
+				atomic.AddInt64(&g.failed, 1)
+			}
+		}()
+	}
+	if j.out != nil {
+		defer j.out.flush()
+	}
 
-			var err error
-			defer m.Manage(ctx, CancellerFunc(cancel), index, &err)
-			err = worker(ctx)
+	if g.reporter != nil {
+		defer func() { g.reporter.complete(j.err) }()
+	}
+	if g.hook != nil {
+		defer func() { g.hook.complete(j.err) }()
+	}
+	if g.logger != nil {
+		// log after the manager, which may recover a panic
+		defer func() {
+			if !j.started.IsZero() {
+				d := ClockFrom(ctx).Now().Sub(j.started)
+				g.logger.finished(ctx, index, d, j.err, !j.ran)
+			}
+		}()
+	}
+	var rec *workerRecord
+	if g.info != nil {
+		rec = &workerRecord{}
+		ctx = context.WithValue(ctx, attemptKey{}, &rec.attempt)
+		defer g.manageInfo(ctx, index, rec, &j.err)
+	} else {
+		defer g.m.Manage(ctx, g.canceller, index, &j.err)
+	}
+	defer func() { j.err = g.annotate(index, j.err) }()
+	if j.skipped != nil {
+		j.err = j.skipped
+		return
+	}
+	if rerr, ok := ctx.Value(rejectKey{}).(error); ok {
+		if rerr != errDropped {
+			j.err = rerr
+		}
+		return
+	}
+	g.gate(ctx)
+	if g.stopped() {
+		j.err = context.Canceled
+		return
+	}
+	if g.admitter != nil {
+		if j.err = g.admitter.admit(ctx, index); j.err != nil {
+			return
+		}
+	}
+	if g.skip && ctx.Err() != nil {
+		j.err = ctx.Err()
+		return
+	}
+	switch g.e.(type) {
+	case lender, weigher:
+		defer j.hold()()
+	}
+	if g.watchdog != nil {
+		defer g.watchdog.watch(ctx, index)()
+	}
+	if g.inflight != nil {
+		defer g.inflight.start(index)()
+	}
+	if g.timeout > 0 {
+		j.w = timeoutWorker(g.timeout, j.worker())
+	}
+	if g.logger != nil {
+		j.started = ClockFrom(ctx).Now()
+		g.logger.started(ctx, index)
+		j.w = ranWorker(j.worker(), &j.ran)
+	}
+	if rec != nil {
+		rec.start = ClockFrom(ctx).Now()
+		rec.attempt = 1
+	}
+	if g.label != "" {
+		labels := pprof.Labels("workgroup", g.label, "worker", strconv.Itoa(index))
+		pprof.Do(ctx, labels, func(ctx context.Context) {
+			j.err = j.call(ctx)
 		})
+		return
 	}
+	j.err = j.call(ctx)
+}
 
-	wg.Wait()
+// workerRecord records the metadata of a worker, see InfoManager.
+type workerRecord struct {
+	start   time.Time
+	attempt int32
+}
 
-	return m.Error()
+// manageInfo calls the ManageInfo method of the manager of the group
+// with the metadata of the worker with the given index. If the manager
+// is wrapped by Recover or Repanic, then a panic of the worker is
+// recovered, which requires this method to be deferred directly.
+func (g *group) manageInfo(ctx context.Context, index int, rec *workerRecord, err *error) {
+	c := g.canceller
+	if w, ok := g.m.(*recoverWrapper); ok {
+		if v := recover(); v != nil {
+			*err = w.recovered(ctx, index, v)
+			c = w.canceller(c)
+		}
+	}
+	info := WorkerInfo{
+		Index:   index,
+		Name:    g.workerName(index),
+		Group:   g.name,
+		Started: rec.start,
+		Attempt: int(atomic.LoadInt32(&rec.attempt)),
+	}
+	if !rec.start.IsZero() {
+		info.Elapsed = ClockFrom(ctx).Now().Sub(rec.start)
+	}
+	g.info.ManageInfo(ctx, c, info, err)
 }
 
-// GroupChan returns a worker that immediately calls
-// WorkChan to execute the group of workers provided
-// by the channel.
-func GroupChan(e Executer, m Manager, g <-chan Worker) Worker {
-	return func(ctx context.Context) error {
-		return WorkChan(ctx, e, m, g)
+// work executes the workers, ws, and waits for them to complete.
+func (g *group) work(ws []Worker) error {
+	g.expect(len(ws))
+	for i, w := range ws {
+		if g.halted() {
+			break
+		}
+		g.execute(i, w)
+	}
+	return g.wait()
+}
+
+// workFor executes the worker, w, n times and waits for them to complete.
+func (g *group) workFor(n int, w IdxWorker) error {
+	w, completed, err := g.checkpointed(w)
+	if err != nil {
+		n = 0
+	}
+
+	skipped := 0
+	for i := range completed {
+		if i >= 0 && i < n {
+			skipped++
+		}
+	}
+
+	g.expect(n - skipped)
+	for i := 0; i < n; i++ {
+		if g.halted() {
+			break
+		}
+		if completed[i] {
+			continue
+		}
+		g.executeFor(i, w)
+	}
+	if werr := g.wait(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// firstIndex returns the index of the first worker of the work group
+// of the worker with the context, ctx, which is one for WorkChan.
+func firstIndex(ctx context.Context) int {
+	if g, ok := ctx.Value(groupKey{}).(*group); ok {
+		return g.first
+	}
+	return 0
+}
+
+// halted reports whether the group is configured by StopIfCancelled
+// and no further workers are to be generated.
+func (g *group) halted() bool {
+	return g.halt && (g.ctx.Err() != nil || g.stopped())
+}
+
+// expect records the total number of workers of the group,
+// if it is known before the workers are submitted.
+func (g *group) expect(n int) {
+	atomic.StoreInt64(&g.expected, int64(n))
+}
+
+// total returns the total number of workers of the group, which
+// is the number of workers submitted so far if it is not known.
+func (g *group) total() int {
+	if n := atomic.LoadInt64(&g.expected); n > 0 {
+		return int(n)
+	}
+	return int(atomic.LoadInt64(&g.submitted))
+}
+
+// report adjusts the number of pending workers by delta
+// and reports the backlog if the group is observed.
+func (g *group) report(delta int) {
+	if g.backlog == nil {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.pending += delta
+	g.backlog(g.pending)
+}
+
+// close cancels the context of the group and releases
+// the resources of the group, such as its temporary directory.
+func (g *group) close() {
+	g.cancel()
+	if g.tmp != nil {
+		g.tmp.remove()
+	}
+}
+
+// wait waits for all workers of the group to complete
+// and then returns the error provided by the manager.
+func (g *group) wait() error {
+	g.wg.Wait()
+	if g.reclaim != nil {
+		g.reclaim()
+	}
+	err := g.m.Error()
+	if err != nil && g.name != "" {
+		err = &FailedGroupError{
+			Group:  g.name,
+			Failed: int(atomic.LoadInt64(&g.failed)),
+			Total:  int(atomic.LoadInt64(&g.submitted)),
+			Err:    err,
+		}
+	}
+	if err != nil {
+		err = interrupted(g.ctx, err)
+	}
+	if g.reporter != nil {
+		g.reporter.finish(err)
+	}
+	if g.logger != nil {
+		g.logger.completed(g.ctx, ClockFrom(g.ctx).Now().Sub(g.start), err)
 	}
+	close(g.done)
+	return err
 }
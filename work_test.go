@@ -2,7 +2,13 @@ package workgroup
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -48,6 +54,38 @@ func TestSimpleWorkFor(t *testing.T) {
 	}
 }
 
+type indexManager struct {
+	Manager
+	mutex   sync.Mutex
+	indexes []int
+}
+
+func (m *indexManager) Manage(ctx context.Context, c Canceller, idx int, err *error) int {
+	m.mutex.Lock()
+	m.indexes = append(m.indexes, idx)
+	m.mutex.Unlock()
+	return m.Manager.Manage(ctx, c, idx, err)
+}
+
+func TestWorkChanIndex(t *testing.T) {
+
+	workers := make(chan Worker, 3)
+	for i := 0; i < 3; i++ {
+		workers <- func(ctx context.Context) error {
+			return nil
+		}
+	}
+	close(workers)
+
+	m := &indexManager{Manager: CancelNeverFirstError()}
+	WorkChan(context.Background(), nil, m, workers)
+	sort.Ints(m.indexes)
+
+	if fmt.Sprint(m.indexes) != "[1 2 3]" {
+		t.Fatalf("Expecting workers indexed from one, got %v", m.indexes)
+	}
+}
+
 func TestSimpleWorkChan(t *testing.T) {
 
 	counts := make([]int, 10000)
@@ -151,9 +189,7 @@ func TestCancelNeverFirstError(t *testing.T) {
 
 	counts := make([]int, 10000)
 
-	m := &AccumulateManager{
-		manager: CancelNeverFirstError(),
-	}
+	m := Accumulate(CancelNeverFirstError())
 
 	err := WorkFor(context.Background(), NewUnlimited(), m, len(counts),
 		func(ctx context.Context, index int) (err error) {
@@ -183,7 +219,7 @@ func TestCancelNeverFirstError(t *testing.T) {
 	if err == nil {
 		t.Errorf("Work group error is nil")
 	}
-	for _, e := range m.Errors {
+	for _, e := range m.Errors() {
 		if e != nil {
 			if e != err {
 				t.Fatal("Work group error is not first error")
@@ -197,9 +233,7 @@ func TestCancelOnFirstError(t *testing.T) {
 
 	counts := make([]int, 10000)
 
-	m := &AccumulateManager{
-		manager: CancelOnFirstError(),
-	}
+	m := Accumulate(CancelOnFirstError())
 
 	err := WorkFor(context.Background(), NewUnlimited(), m, len(counts),
 		func(ctx context.Context, index int) (err error) {
@@ -229,7 +263,7 @@ func TestCancelOnFirstError(t *testing.T) {
 		t.Errorf("Work group error is nil")
 	}
 	errored := false
-	for i, e := range m.Errors {
+	for i, e := range m.Errors() {
 		if errored {
 			if e == nil {
 				t.Fatalf("Expecting accumulated error (%d) to not be nil", i)
@@ -252,9 +286,7 @@ func TestCancelOnFirstSuccess(t *testing.T) {
 
 	counts := make([]int, 10000)
 
-	m := &AccumulateManager{
-		manager: CancelOnFirstSuccess(),
-	}
+	m := Accumulate(CancelOnFirstSuccess())
 
 	err := WorkFor(context.Background(), NewUnlimited(), m, len(counts),
 		func(ctx context.Context, index int) (err error) {
@@ -284,7 +316,7 @@ func TestCancelOnFirstSuccess(t *testing.T) {
 		t.Errorf("Work group error is not nil: %s", err)
 	}
 	success := false
-	for i, e := range m.Errors {
+	for i, e := range m.Errors() {
 		if success {
 			if e == nil {
 				t.Fatalf("Expecting accumulated error (%d) to not be nil", i)
@@ -308,9 +340,7 @@ func TestCancelOnFirstComplete(t *testing.T) {
 
 	counts := make([]int, 10000)
 
-	m := &AccumulateManager{
-		manager: CancelOnFirstComplete(),
-	}
+	m := Accumulate(CancelOnFirstComplete())
 
 	err := WorkFor(context.Background(), NewUnlimited(), m, len(counts),
 		func(ctx context.Context, index int) (err error) {
@@ -335,7 +365,7 @@ func TestCancelOnFirstComplete(t *testing.T) {
 	if err != nil {
 		t.Errorf("Work group error is not nil: %s", err)
 	}
-	for i, e := range m.Errors {
+	for i, e := range m.Errors() {
 		if i > 0 {
 			if e != nil && e != context.Canceled {
 				t.Fatalf("Expecting accumulated error (%d) to be canceled: %s", i, e)
@@ -402,9 +432,13 @@ func TestRepanicManager(t *testing.T) {
 		}
 
 		if v := recover(); v != nil {
-			if v != "worker 500 failed" {
+			perr, ok := v.(*PanicError)
+			if !ok || perr.Value() != "worker 500 failed" {
 				t.Fatalf("Work group panic value incorrect")
 			}
+			if !strings.Contains(perr.Error(), "TestRepanicManager") {
+				t.Fatalf("Work group panic does not include the original stack:\n%s", perr.Error())
+			}
 		} else {
 			t.Fatalf("Work group did not panic")
 		}
@@ -430,3 +464,493 @@ func TestRepanicManager(t *testing.T) {
 		},
 	)
 }
+
+func TestRateLimitedWorkFor(t *testing.T) {
+
+	counts := make([]int, 30)
+
+	start := time.Now()
+	WorkFor(context.Background(), NewRateLimited(200, 10), nil, len(counts),
+		func(ctx context.Context, index int) error {
+			counts[index]++
+			return nil
+		},
+	)
+	elapsed := time.Since(start)
+
+	// The first 10 workers start immediately, the
+	// remaining 20 workers start at 200 per second.
+	if elapsed < 90*time.Millisecond {
+		t.Errorf("Work group completed too quickly: %s", elapsed)
+	}
+
+	for _, c := range counts {
+		if c != 1 {
+			t.Errorf("Worker %d has not completed", c)
+		}
+	}
+}
+
+func TestSerialWorkFor(t *testing.T) {
+
+	var order []int
+	err := WorkFor(context.Background(), NewSerial(), CancelNeverFirstError(), 10,
+		func(ctx context.Context, index int) error {
+			order = append(order, index)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, index := range order {
+		if i != index {
+			t.Fatalf("Workers not executed in submission order: %v", order)
+		}
+	}
+}
+
+func TestInheritLimitedWork(t *testing.T) {
+
+	var mutex sync.Mutex
+	var active, peak int
+
+	worker := func(ctx context.Context, index int) error {
+		mutex.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return nil
+	}
+
+	err := WorkFor(context.Background(), NewLimited(4), nil, 8,
+		func(ctx context.Context, index int) error {
+			return WorkFor(ctx, Inherit(), nil, 100, worker)
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+	if peak > 4 {
+		t.Fatalf("Nested work groups exceeded inherited limit: %d", peak)
+	}
+}
+
+func TestLendLimitedWork(t *testing.T) {
+
+	var mutex sync.Mutex
+	var active, peak int
+
+	worker := func(ctx context.Context, index int) error {
+		mutex.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return nil
+	}
+
+	// the slot of a worker is lent to one of its nested groups at a time
+	e := NewLimited(2)
+	var escaped context.Context
+	err := WorkFor(context.Background(), e, nil, 2,
+		func(ctx context.Context, index int) error {
+			if index == 0 {
+				escaped = ctx
+			}
+			Go(ctx, func(ctx context.Context) {
+				WorkFor(ctx, e, nil, 10, worker)
+			})
+			return WorkFor(ctx, e, nil, 10, worker)
+		},
+	)
+	if err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+
+	// the worker of an escaped context holds no slot to lend
+	if err := WorkFor(escaped, e, nil, 10, worker); err != nil {
+		t.Fatalf("Work group error is not nil: %s", err)
+	}
+	if peak > 2 {
+		t.Fatalf("Nested work groups exceeded the limit: %d", peak)
+	}
+}
+
+func TestInheritExecuterContext(t *testing.T) {
+
+	var executers []Executer
+
+	e := NewLimited(4)
+	ctx := InheritExecuter(context.Background())
+
+	Work(ctx, e, nil,
+		Group(nil, nil,
+			func(ctx context.Context) error {
+				executers = append(executers, ctx.Value(executerKey{}).(Executer))
+				return nil
+			},
+		),
+	)
+	Work(context.Background(), e, nil,
+		Group(nil, nil,
+			func(ctx context.Context) error {
+				executers = append(executers, ctx.Value(executerKey{}).(Executer))
+				return nil
+			},
+		),
+	)
+
+	if executers[0] != e {
+		t.Fatalf("Nested work group did not inherit executer")
+	}
+	if executers[1] == e {
+		t.Fatalf("Nested work group inherited executer without opting in")
+	}
+}
+
+func TestCancelledWorkerContract(t *testing.T) {
+
+	newExecuters := map[string]func(ctx context.Context) Executer{
+		"unlimited": func(ctx context.Context) Executer { return NewUnlimited() },
+		"serial":    func(ctx context.Context) Executer { return NewSerial() },
+		"limited":   func(ctx context.Context) Executer { return NewLimited(1) },
+		"pool":      func(ctx context.Context) Executer { return NewPool(ctx, 1) },
+	}
+
+	for name, newExecuter := range newExecuters {
+		for _, skip := range []bool{false, true} {
+			pctx, cancel := context.WithCancel(context.Background())
+			ctx, stop := context.WithCancel(pctx)
+			if skip {
+				ctx = SkipIfCancelled(ctx)
+			}
+			stop()
+
+			var invoked int32
+			err := WorkFor(ctx, newExecuter(pctx), CancelNeverFirstError(), 5, func(ctx context.Context, index int) error {
+				atomic.AddInt32(&invoked, 1)
+				return nil
+			})
+			cancel()
+
+			if skip && (invoked != 0 || err != context.Canceled) {
+				t.Errorf("%s: expecting no workers invoked, got %d: %v", name, invoked, err)
+			}
+			if !skip && (invoked != 5 || err != nil) {
+				t.Errorf("%s: expecting 5 workers invoked, got %d: %v", name, invoked, err)
+			}
+		}
+	}
+}
+
+// countingExecuter counts the functions submitted to the executer, e.
+type countingExecuter struct {
+	e Executer
+	n int32
+}
+
+func (c *countingExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	atomic.AddInt32(&c.n, 1)
+	c.e.Execute(ctx, f)
+}
+
+func TestSkipIfCancelledDispatch(t *testing.T) {
+
+	e := &countingExecuter{e: NewSerial()}
+	ctx := SkipIfCancelled(context.Background())
+
+	var invoked int32
+	err := WorkFor(ctx, e, CancelOnFirstError(), 5, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&invoked, 1)
+		if index == 1 {
+			return fmt.Errorf("worker %d failed", index)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "worker 1 failed" {
+		t.Fatalf("Expecting error of worker 1, got %v", err)
+	}
+	if e.n != 2 || invoked != 2 {
+		t.Fatalf("Expecting 2 workers submitted and invoked, got %d and %d", e.n, invoked)
+	}
+}
+
+func TestStopIfCancelled(t *testing.T) {
+
+	m := Accumulate(CancelOnFirstError())
+	ctx := StopIfCancelled(context.Background())
+
+	var invoked int32
+	err := WorkFor(ctx, NewLimited(4), m, 50000000, func(ctx context.Context, index int) error {
+		if atomic.AddInt32(&invoked, 1) == 100 {
+			return fmt.Errorf("worker %d failed", index)
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("Expecting error of the failed worker")
+	}
+	if n := len(m.Errors()); n > 200 {
+		t.Fatalf("Expecting no workers generated after cancellation, got %d managed", n)
+	}
+}
+
+func TestWorkChanCancel(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g := make(chan Worker)
+	go func() {
+		g <- func(ctx context.Context) error {
+			cancel()
+			return nil
+		}
+		// the producer stalls without closing the channel
+	}()
+
+	done := make(chan error)
+	go func() {
+		done <- WorkChan(ctx, nil, nil, g)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expecting cancelled WorkChan to return with stalled producer")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	g = make(chan Worker)
+	go func() {
+		defer close(g)
+		for i := 0; i < 3; i++ {
+			g <- func(ctx context.Context) error { return nil }
+		}
+	}()
+
+	var report Report
+	ctx = WithReport(DrainOnCancel(SkipIfCancelled(ctx)), func(r Report) { report = r })
+	err := WorkChan(ctx, nil, CancelNeverFirstError(), g)
+	if err != context.Canceled || report.Workers != 3 || report.Failed != 3 {
+		t.Fatalf("Expecting 3 workers drained and skipped, got %+v", report)
+	}
+}
+
+func TestWorkChanFor(t *testing.T) {
+
+	items := make(chan int)
+	go func() {
+		defer close(items)
+		for i := 1; i <= 10; i++ {
+			items <- i
+		}
+	}()
+
+	var sum int64
+	err := WorkChanFor(context.Background(), NewLimited(3), nil, items, func(ctx context.Context, item int) error {
+		atomic.AddInt64(&sum, int64(item))
+		return nil
+	})
+	if err != nil || sum != 55 {
+		t.Fatalf("Expecting sum of 55, got %d: %v", sum, err)
+	}
+}
+
+func TestWorkChanForIndex(t *testing.T) {
+
+	items := make(chan string, 3)
+	items <- "a"
+	items <- "b"
+	items <- "c"
+	close(items)
+
+	m := &indexManager{Manager: CancelNeverFirstError()}
+	WorkChanFor(context.Background(), nil, m, items, func(ctx context.Context, item string) error {
+		return nil
+	})
+	sort.Ints(m.indexes)
+
+	if fmt.Sprint(m.indexes) != "[1 2 3]" {
+		t.Fatalf("Expecting items indexed from one, got %v", m.indexes)
+	}
+}
+
+func TestWorkForChunked(t *testing.T) {
+
+	for _, chunkSize := range []int{0, 1, 7, 1000, 2000} {
+		var chunks, sum int64
+		err := WorkForChunked(context.Background(), NewLimited(4), nil, 1000, chunkSize, func(ctx context.Context, start, end int) error {
+			atomic.AddInt64(&chunks, 1)
+			for i := start; i < end; i++ {
+				atomic.AddInt64(&sum, int64(i))
+			}
+			return nil
+		})
+		if err != nil || sum != 499500 {
+			t.Fatalf("Chunk size %d: expecting sum of 499500, got %d: %v", chunkSize, sum, err)
+		}
+		if chunkSize > 0 && chunks != int64((1000+chunkSize-1)/chunkSize) {
+			t.Fatalf("Chunk size %d: unexpected number of chunks %d", chunkSize, chunks)
+		}
+	}
+}
+
+func TestBoundExecuters(t *testing.T) {
+
+	procs := runtime.GOMAXPROCS(0)
+	if n := cap(NewCPUBound().(*limited).ch); n != procs {
+		t.Fatalf("Expecting CPU bound limit of %d, got %d", procs, n)
+	}
+	if n := cap(NewIOBound(4).(*limited).ch); n != 4*procs {
+		t.Fatalf("Expecting I/O bound limit of %d, got %d", 4*procs, n)
+	}
+	if n := cap(NewIOBound(0).(*limited).ch); n != procs {
+		t.Fatalf("Expecting I/O bound limit of %d, got %d", procs, n)
+	}
+}
+
+func TestIndexFromContext(t *testing.T) {
+
+	if _, ok := IndexFromContext(context.Background()); ok {
+		t.Fatalf("Expecting no index outside of a worker")
+	}
+	err := WorkFor(context.Background(), nil, nil, 10, func(ctx context.Context, index int) error {
+		if i, ok := IndexFromContext(ctx); !ok || i != index {
+			return fmt.Errorf("expecting index %d, got %d", index, i)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContextDecorator(t *testing.T) {
+
+	type requestKey struct{}
+
+	ctx := WithContextDecorator(context.Background(), func(ctx context.Context, index int) context.Context {
+		return context.WithValue(ctx, requestKey{}, fmt.Sprintf("request-%d", index))
+	})
+	err := WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+		if id := ctx.Value(requestKey{}); id != fmt.Sprintf("request-%d", index) {
+			return fmt.Errorf("unexpected request ID of worker %d: %v", index, id)
+		}
+		return Work(ctx, nil, nil, func(ctx context.Context) error {
+			if id := ctx.Value(requestKey{}); id != fmt.Sprintf("request-%d", index) {
+				return fmt.Errorf("unexpected request ID of nested worker: %v", id)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSharedLimit(t *testing.T) {
+
+	e := NewLimited(3)
+
+	var running, max int32
+	work := func(ctx context.Context, index int) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+
+	// concurrent and nested work groups share the limit
+	err := WorkFor(context.Background(), nil, nil, 4, func(ctx context.Context, index int) error {
+		return WorkFor(ctx, e, nil, 4, func(ctx context.Context, index int) error {
+			if index == 0 {
+				return WorkFor(ctx, Inherit(), nil, 4, work)
+			}
+			return work(ctx, index)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if max > 3 {
+		t.Fatalf("Expecting at most 3 concurrent workers, got %d", max)
+	}
+}
+
+func TestNewContext(t *testing.T) {
+
+	e := NewLimited(2)
+	ctx := NewContext(context.Background(), e, func() Manager {
+		return CancelNeverFirstError()
+	})
+
+	failed := errors.New("failed")
+	var completed int32
+	err := WorkFor(ctx, nil, nil, 4, func(ctx context.Context, index int) error {
+		if index == 0 {
+			return failed
+		}
+		// nested groups use the configured executer
+		return Work(ctx, nil, nil, func(ctx context.Context) error {
+			if ctx.Err() == nil {
+				atomic.AddInt32(&completed, 1)
+			}
+			return nil
+		})
+	})
+	if err != failed || completed != 3 {
+		t.Fatalf("Expecting 3 workers completed with error %v, got %d: %v", failed, completed, err)
+	}
+	// the statistics are recorded after the workers complete
+	deadline := time.Now().Add(time.Second)
+	for e.(StatsProvider).Stats().Completed != 7 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := e.(StatsProvider).Stats().Completed; n != 7 {
+		t.Fatalf("Expecting 7 workers executed by the configured executer, got %d", n)
+	}
+}
+
+func benchmarkWorkFor(b *testing.B, e Executer) {
+	ctx := context.Background()
+	m := CancelNeverFirstError()
+	b.ReportAllocs()
+	b.ResetTimer()
+	WorkFor(ctx, e, m, b.N, func(ctx context.Context, index int) error {
+		return nil
+	})
+}
+
+func BenchmarkWorkForSerial(b *testing.B) {
+	benchmarkWorkFor(b, NewSerial())
+}
+
+func BenchmarkWorkForLimited(b *testing.B) {
+	benchmarkWorkFor(b, NewLimited(4))
+}
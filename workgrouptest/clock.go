@@ -0,0 +1,146 @@
+// Package workgrouptest provides utilities for testing code
+// that is built on the workgroup package.
+package workgrouptest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dxmaxwell/workgroup"
+)
+
+// Clock is a fake clock that implements workgroup.Clock. The time
+// of the clock only changes when it is advanced, so that tests of
+// time-based components do not have to wait in real time. Configure
+// the clock with workgroup.WithClock.
+type Clock struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*timer
+}
+
+// NewClock returns a fake clock with the current time, t.
+func NewClock(t time.Time) *Clock {
+	c := &Clock{now: t}
+	c.cond = sync.NewCond(&c.mutex)
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires when the
+// clock is advanced by at least the duration, d.
+func (c *Clock) NewTimer(d time.Duration) workgroup.Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &timer{
+		c:        c,
+		deadline: c.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance advances the current time of the clock by the
+// duration, d, and fires the timers that have expired.
+func (c *Clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.ch <- c.now
+		}
+	}
+	for i := len(pending); i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = pending
+}
+
+// Timers returns the number of timers that have not yet fired.
+func (c *Clock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are waiting to fire. It
+// is used to wait for the code under test to start waiting on the
+// clock before the clock is advanced.
+func (c *Clock) BlockUntil(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// waitTimers is the same as BlockUntil, except that it stops waiting
+// when the channel, done, is closed and reports whether the timers are
+// waiting to fire.
+func (c *Clock) waitTimers(n int, done <-chan struct{}) bool {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-done:
+			c.mutex.Lock()
+			c.cond.Broadcast()
+			c.mutex.Unlock()
+		case <-stop:
+		}
+	}()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for len(c.timers) < n {
+		select {
+		case <-done:
+			return false
+		default:
+		}
+		c.cond.Wait()
+	}
+	return true
+}
+
+type timer struct {
+	c        *Clock
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *timer) Stop() bool {
+	t.c.mutex.Lock()
+	defer t.c.mutex.Unlock()
+
+	for i, p := range t.c.timers {
+		if p == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package workgrouptest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dxmaxwell/workgroup"
+)
+
+func TestClockRateLimited(t *testing.T) {
+
+	clock := NewClock(time.Unix(0, 0))
+	ctx := workgroup.WithClock(context.Background(), clock)
+
+	done := make(chan error)
+	go func() {
+		e := workgroup.NewRateLimited(1, 1)
+		done <- workgroup.WorkFor(ctx, e, nil, 3, func(ctx context.Context, index int) error {
+			return nil
+		})
+	}()
+
+	// The first worker starts immediately, the remaining
+	// workers wait for one second each on the clock.
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Work group did not complete after advancing the clock")
+	}
+	if clock.Now() != time.Unix(2, 0) {
+		t.Fatalf("Unexpected clock time: %s", clock.Now())
+	}
+}
+
+func TestClockPoolIdleTimeout(t *testing.T) {
+
+	clock := NewClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(workgroup.WithClock(context.Background(), clock))
+	defer cancel()
+
+	p := workgroup.NewPool(ctx, 2, workgroup.WithIdleTimeout(time.Minute))
+
+	clock.BlockUntil(2)
+	if n := p.Workers(); n != 2 {
+		t.Fatalf("Expecting 2 workers before the idle timeout, got %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	for p.Workers() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClockWorkerTimeout(t *testing.T) {
+
+	clock := NewClock(time.Unix(0, 0))
+	ctx := workgroup.WithClock(context.Background(), clock)
+	ctx = workgroup.WithWorkerTimeout(ctx, time.Hour)
+
+	done := make(chan error)
+	go func() {
+		done <- workgroup.Work(ctx, nil, nil, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	if err := <-done; !errors.Is(err, workgroup.ErrWorkerTimeout) {
+		t.Fatalf("Expecting worker timeout error, got %v", err)
+	}
+}
+
+func TestClockErrorBudget(t *testing.T) {
+
+	clock := NewClock(time.Unix(0, 0))
+	ctx := workgroup.WithClock(context.Background(), clock)
+
+	b := workgroup.NewErrorBudget(4, 0.5, 4, time.Minute)
+	workgroup.WorkFor(ctx, b, b.Manager(nil), 4, func(ctx context.Context, index int) error {
+		return errors.New("failed")
+	})
+	if !b.Paused() {
+		t.Fatalf("Expecting admission paused")
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- workgroup.WorkFor(ctx, b, b.Manager(nil), 2, func(ctx context.Context, index int) error {
+			return nil
+		})
+	}()
+
+	// the workers are admitted once the cooldown has elapsed
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b.Paused() || b.Limit() != 2 {
+		t.Fatalf("Expecting admission resumed at the limit of 2, got %d", b.Limit())
+	}
+}
+
+func TestClockWatchdog(t *testing.T) {
+
+	clock := NewClock(time.Unix(0, 0))
+	stalled := make(chan workgroup.WorkerInfo, 3)
+	ctx := workgroup.WithClock(context.Background(), clock)
+	ctx = workgroup.WithWatchdog(ctx, time.Minute, func(info workgroup.WorkerInfo) {
+		stalled <- info
+	})
+
+	var returned sync.WaitGroup
+	returned.Add(2)
+	running := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- workgroup.WorkFor(ctx, nil, nil, 3, func(ctx context.Context, index int) error {
+			if index == 1 {
+				close(running)
+				<-release
+				return nil
+			}
+			returned.Done()
+			return nil
+		})
+	}()
+
+	// The clock is advanced once only the timer
+	// of the running worker is waiting to fire.
+	<-running
+	returned.Wait()
+	for clock.Timers() > 1 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Minute)
+
+	info := <-stalled
+	if info.Index != 1 || info.Started != time.Unix(0, 0) {
+		t.Fatalf("Expecting worker 1 stalled, got %+v", info)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stalled) != 0 {
+		t.Fatalf("Watchdog fired for workers that completed")
+	}
+}
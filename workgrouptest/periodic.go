@@ -0,0 +1,110 @@
+package workgrouptest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dxmaxwell/workgroup"
+)
+
+// Periodic runs a periodic worker with workgroup.Repeat on the virtual
+// time of a fake clock, and records the times that the runs of the worker
+// are started, so that a test can advance the time by whole intervals,
+// assert the exact runs that were scheduled, and verify that cancellation
+// stops future runs. The clock is assumed to only be waited on by Repeat,
+// so the worker should not wait on the clock, and the runs are assumed to
+// complete promptly unless the test coordinates them, for example, to
+// exercise the overlap policy.
+type Periodic struct {
+	clock  *Clock
+	d      time.Duration
+	start  time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+
+	mutex sync.Mutex
+	fired []time.Duration
+}
+
+// StartPeriodic configures the context, ctx, with the clock, and starts
+// workgroup.Repeat on a new goroutine with the executer, e, the manager,
+// m, the interval, d, the overlap policy and the worker, w, see Repeat.
+// If e is nil, then the runs are executed by workgroup.NewSerial, so that
+// each run completes before Repeat waits for the next interval.
+func StartPeriodic(ctx context.Context, clock *Clock, e workgroup.Executer, m workgroup.Manager, d time.Duration, overlap workgroup.Overlap, w workgroup.Worker) *Periodic {
+	if e == nil {
+		e = workgroup.NewSerial()
+	}
+
+	ctx, cancel := context.WithCancel(workgroup.WithClock(ctx, clock))
+	p := &Periodic{
+		clock:  clock,
+		d:      d,
+		start:  clock.Now(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(p.done)
+		p.err = workgroup.Repeat(ctx, &periodicExecuter{e: e, p: p}, m, d, overlap, w)
+	}()
+	p.settle()
+	return p
+}
+
+// periodicExecuter records the time that each run is submitted by Repeat.
+type periodicExecuter struct {
+	e workgroup.Executer
+	p *Periodic
+}
+
+func (e *periodicExecuter) Execute(ctx context.Context, f func(context.Context)) {
+	e.p.mutex.Lock()
+	e.p.fired = append(e.p.fired, e.p.clock.Now().Sub(e.p.start))
+	e.p.mutex.Unlock()
+	e.e.Execute(ctx, f)
+}
+
+// settle waits until Repeat is waiting for the next interval,
+// or has returned, and reports whether it is waiting.
+func (p *Periodic) settle() bool {
+	return p.clock.waitTimers(1, p.done)
+}
+
+// Advance advances the clock by n intervals, one interval at a time
+// once Repeat is waiting for it, and returns once Repeat has submitted
+// the runs that are due and is waiting for the next interval, or has
+// returned.
+func (p *Periodic) Advance(n int) {
+	for i := 0; i < n && p.settle(); i++ {
+		p.clock.Advance(p.d)
+	}
+	p.settle()
+}
+
+// Fired returns the times that the runs of the worker were submitted,
+// relative to the time of the clock when the worker was started.
+func (p *Periodic) Fired() []time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	fired := make([]time.Duration, len(p.fired))
+	copy(fired, p.fired)
+	return fired
+}
+
+// Done returns a channel that is closed when Repeat has returned,
+// for example, because its manager stopped the schedule.
+func (p *Periodic) Done() <-chan struct{} {
+	return p.done
+}
+
+// Stop cancels the context of Repeat, waits for it to return and
+// returns its error. The worker is not run again, even if the clock
+// is advanced, which can be verified with Fired.
+func (p *Periodic) Stop() error {
+	p.cancel()
+	<-p.done
+	return p.err
+}
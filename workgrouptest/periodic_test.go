@@ -0,0 +1,65 @@
+package workgrouptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dxmaxwell/workgroup"
+)
+
+func TestPeriodic(t *testing.T) {
+
+	clock := NewClock(time.Unix(0, 0))
+	p := StartPeriodic(context.Background(), clock, nil, nil, time.Second, workgroup.OverlapSkip, func(ctx context.Context) error {
+		return nil
+	})
+
+	p.Advance(3)
+	if fired := fmt.Sprint(p.Fired()); fired != "[0s 1s 2s 3s]" {
+		t.Fatalf("Unexpected runs: %s", fired)
+	}
+
+	// no run is scheduled once the worker is stopped
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p.Advance(3)
+	clock.Advance(time.Minute)
+	if fired := fmt.Sprint(p.Fired()); fired != "[0s 1s 2s 3s]" {
+		t.Fatalf("Unexpected runs after stop: %s", fired)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("Expecting no timers after stop, got %d", n)
+	}
+}
+
+func TestPeriodicManager(t *testing.T) {
+
+	failed := errors.New("failed")
+
+	clock := NewClock(time.Unix(0, 0))
+	runs := 0
+	p := StartPeriodic(context.Background(), clock, nil, workgroup.CancelOnFirstError(), time.Minute, workgroup.OverlapSkip, func(ctx context.Context) error {
+		if runs++; runs == 3 {
+			return failed
+		}
+		return nil
+	})
+
+	// the schedule is stopped by the manager
+	p.Advance(5)
+	select {
+	case <-p.Done():
+	default:
+		t.Fatalf("Expecting schedule stopped by the manager")
+	}
+	if fired := fmt.Sprint(p.Fired()); fired != "[0s 1m0s 2m0s]" {
+		t.Fatalf("Unexpected runs: %s", fired)
+	}
+	if err := p.Stop(); err != failed {
+		t.Fatalf("Expecting error %v, got %v", failed, err)
+	}
+}
@@ -0,0 +1,207 @@
+package workgrouptest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dxmaxwell/workgroup"
+)
+
+// EventKind is the kind of an event recorded by a Scheduler.
+type EventKind int
+
+const (
+	// Started is the event of a worker that has started.
+	Started EventKind = iota
+
+	// Completed is the event of a worker that has completed.
+	Completed
+)
+
+func (k EventKind) String() string {
+	if k == Completed {
+		return "completed"
+	}
+	return "started"
+}
+
+// Event is the start or completion of a worker recorded by a Scheduler.
+type Event struct {
+	// Index is the index of the worker, or -1 if the
+	// function executed was not a worker of a work group.
+	Index int
+
+	// Kind is the kind of the event.
+	Kind EventKind
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%d %s", e.Index, e.Kind)
+}
+
+type scheduled struct {
+	index int
+	ctx   context.Context
+	f     func(context.Context)
+}
+
+// Scheduler is an executer that executes the functions one at a time, in
+// an order that can be controlled, and records the interleaving of the
+// starts and completions of the workers, so that a concurrency-ordering
+// bug in code built on the workgroup package can be reproduced and kept
+// as a regression test. The functions are identified by the index of the
+// worker, see workgroup.IndexFromContext, so a scheduler is intended for
+// the workers of one work group. Since only one function runs at a time,
+// workers that wait for each other to run will deadlock.
+type Scheduler struct {
+	mutex   sync.Mutex
+	order   []int
+	next    int
+	queue   []scheduled
+	running bool
+	events  []Event
+}
+
+// NewScheduler returns a scheduler that starts the workers in the order
+// of their indices in the schedule, order, as returned by Schedule for
+// an earlier run, waiting for each worker in turn to be submitted. The
+// functions that are not in the schedule are started in the order that
+// they were submitted, once the schedule is exhausted. If order is nil,
+// then the functions are started in the order that they were submitted.
+func NewScheduler(order []int) *Scheduler {
+	return &Scheduler{order: order}
+}
+
+// Execute queues the function, f, to be started by the scheduler
+// in its turn and returns without waiting for it to be started.
+func (s *Scheduler) Execute(ctx context.Context, f func(context.Context)) {
+	index, ok := workgroup.IndexFromContext(ctx)
+	if !ok {
+		index = -1
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.queue = append(s.queue, scheduled{index: index, ctx: ctx, f: f})
+	s.dispatch()
+}
+
+// dispatch starts the next function in its turn, if no function is
+// running and it has been submitted, the mutex must be held.
+func (s *Scheduler) dispatch() {
+	if s.running || len(s.queue) == 0 {
+		return
+	}
+
+	i := 0
+	if s.next < len(s.order) {
+		i = -1
+		for j, p := range s.queue {
+			if p.index == s.order[s.next] {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			// wait for the worker to be submitted
+			return
+		}
+		s.next++
+	}
+
+	p := s.queue[i]
+	s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	s.running = true
+	s.events = append(s.events, Event{Index: p.index, Kind: Started})
+	go func() {
+		defer func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			s.events = append(s.events, Event{Index: p.index, Kind: Completed})
+			s.running = false
+			s.dispatch()
+		}()
+		p.f(p.ctx)
+	}()
+}
+
+// Trace returns the events recorded by the scheduler, in order.
+func (s *Scheduler) Trace() []Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := make([]Event, len(s.events))
+	copy(events, s.events)
+	return events
+}
+
+// Schedule returns the indices of the workers in the order that
+// they were started, which can be replayed with NewScheduler.
+func (s *Scheduler) Schedule() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var order []int
+	for _, e := range s.events {
+		if e.Kind == Started {
+			order = append(order, e.Index)
+		}
+	}
+	return order
+}
+
+// ScheduleError is the error of a run explored by Explore, with
+// the schedule that produced it, so that it can be replayed.
+type ScheduleError struct {
+	Schedule []int
+	Err      error
+}
+
+func (e *ScheduleError) Error() string {
+	return fmt.Sprintf("schedule %v: %v", e.Schedule, e.Err)
+}
+
+func (e *ScheduleError) Unwrap() error {
+	return e.Err
+}
+
+// Explore calls the function, fn, with a scheduler for each permutation
+// of the order of the workers with the indices 0 to n-1, in lexicographic
+// order, up to bound permutations, or all of them if bound <= 0. The first
+// error returned by fn is returned as a ScheduleError with the schedule.
+func Explore(n, bound int, fn func(s *Scheduler) error) error {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	for k := 0; bound <= 0 || k < bound; k++ {
+		schedule := make([]int, n)
+		copy(schedule, order)
+		if err := fn(NewScheduler(schedule)); err != nil {
+			return &ScheduleError{Schedule: schedule, Err: err}
+		}
+		if !nextPermutation(order) {
+			break
+		}
+	}
+	return nil
+}
+
+// nextPermutation rearranges the indices, p, into the next permutation in
+// lexicographic order, and reports false if p is the last permutation.
+func nextPermutation(p []int) bool {
+	i := len(p) - 2
+	for i >= 0 && p[i] >= p[i+1] {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	j := len(p) - 1
+	for p[j] <= p[i] {
+		j--
+	}
+	p[i], p[j] = p[j], p[i]
+	sort.Ints(p[i+1:])
+	return true
+}
@@ -0,0 +1,77 @@
+package workgrouptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dxmaxwell/workgroup"
+)
+
+func TestSchedulerReplay(t *testing.T) {
+
+	var mutex sync.Mutex
+	var order []int
+	run := func(s *Scheduler) error {
+		order = nil
+		return workgroup.WorkFor(context.Background(), s, nil, 4, func(ctx context.Context, index int) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, index)
+			return nil
+		})
+	}
+
+	s := NewScheduler(nil)
+	if err := run(s); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(s.Schedule()) != "[0 1 2 3]" {
+		t.Fatalf("Expecting workers started in order of submission, got %v", s.Schedule())
+	}
+	if trace := fmt.Sprint(s.Trace()[:2]); trace != "[0 started 0 completed]" {
+		t.Fatalf("Unexpected trace: %s", trace)
+	}
+
+	s = NewScheduler([]int{2, 0, 3, 1})
+	if err := run(s); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(order) != "[2 0 3 1]" || fmt.Sprint(s.Schedule()) != "[2 0 3 1]" {
+		t.Fatalf("Expecting workers replayed in order [2 0 3 1], got %v", order)
+	}
+}
+
+func TestExplore(t *testing.T) {
+
+	runs := 0
+	err := Explore(3, 0, func(s *Scheduler) error {
+		runs++
+		return workgroup.WorkFor(context.Background(), s, nil, 3, func(ctx context.Context, index int) error {
+			return nil
+		})
+	})
+	if err != nil || runs != 6 {
+		t.Fatalf("Expecting 6 schedules explored, got %d: %v", runs, err)
+	}
+
+	// the last writer wins, which is a bug if worker 0 is last
+	lost := errors.New("update lost")
+	err = Explore(3, 10, func(s *Scheduler) error {
+		var last int
+		err := workgroup.WorkFor(context.Background(), s, nil, 3, func(ctx context.Context, index int) error {
+			last = index
+			return nil
+		})
+		if err == nil && last == 0 {
+			err = lost
+		}
+		return err
+	})
+	var serr *ScheduleError
+	if !errors.As(err, &serr) || !errors.Is(err, lost) || fmt.Sprint(serr.Schedule) != "[1 2 0]" {
+		t.Fatalf("Expecting the schedule [1 2 0] to lose the update, got %v", err)
+	}
+}